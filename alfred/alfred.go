@@ -2,12 +2,17 @@ package alfred
 
 import (
 	"fmt"
+	"html"
 	"strings"
+	"unicode"
 
 	"github.com/mnadel/freddiebear/db"
 	"github.com/mnadel/freddiebear/ext"
 )
 
+// LockIndicator precedes the titles of encrypted notes
+const LockIndicator = "🔒 "
+
 type Source = *db.Result
 type Target = *db.Result
 
@@ -53,6 +58,9 @@ func AlfredOpenXML(results db.Results, optShowTags bool) string {
 	for _, item := range results {
 		builder.WriteString(`<item valid="yes">`)
 		builder.WriteString(`<title>`)
+		if item.Encrypted {
+			builder.WriteString(LockIndicator)
+		}
 		builder.WriteString(item.TitleCase())
 		builder.WriteString(`</title>`)
 
@@ -91,7 +99,7 @@ func AlfredCreateXML(searchTerm string) string {
 	builder.WriteString(title)
 	builder.WriteString(`</title>`)
 	builder.WriteString(`<arg>`)
-	ext.WriteKeyValue(&builder, `create`, title)
+	builder.WriteString(ext.NewArg(ext.Create, ext.Payload{Title: title}).String())
 	builder.WriteString(`</arg>`)
 	builder.WriteString(`</item>`)
 
@@ -99,3 +107,97 @@ func AlfredCreateXML(searchTerm string) string {
 
 	return builder.String()
 }
+
+// AlfredAttachmentXML generates a result for each note with attachments whose text matched the search
+// term, with the matching attachments' filenames and a snippet of the first one's text as the subtitle
+func AlfredAttachmentXML(matches []*db.AttachmentMatch, term string) string {
+	builder := strings.Builder{}
+
+	builder.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	builder.WriteString(`<items>`)
+
+	for _, group := range groupByNote(matches) {
+		filenames := make([]string, 0, len(group))
+		for _, match := range group {
+			filenames = append(filenames, match.Filename)
+		}
+
+		builder.WriteString(`<item valid="yes">`)
+		builder.WriteString(`<title>`)
+		builder.WriteString(group[0].Note.TitleCase())
+		builder.WriteString(`</title>`)
+
+		builder.WriteString(`<subtitle>`)
+		builder.WriteString(html.EscapeString(fmt.Sprintf("%s: %s", strings.Join(filenames, ", "), Snippet(group[0].Text, term, 30))))
+		builder.WriteString(`</subtitle>`)
+
+		builder.WriteString(`<arg>`)
+		builder.WriteString(group[0].Note.ID)
+		builder.WriteString(`</arg>`)
+		builder.WriteString(`</item>`)
+	}
+
+	builder.WriteString(`</items>`)
+
+	return builder.String()
+}
+
+// AlfredEmptyXML generates an empty list of results
+func AlfredEmptyXML() string {
+	return `<?xml version="1.0" encoding="utf-8"?><items></items>`
+}
+
+// groupByNote groups the matches by their note, in the order each note first matched
+func groupByNote(matches []*db.AttachmentMatch) [][]*db.AttachmentMatch {
+	groups := make([][]*db.AttachmentMatch, 0)
+	index := make(map[string]int)
+
+	for _, match := range matches {
+		i, ok := index[match.Note.ID]
+		if !ok {
+			i = len(groups)
+			index[match.Note.ID] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], match)
+	}
+
+	return groups
+}
+
+// Snippet returns the text surrounding the first (case-insensitive) occurrence of term, with up to
+// radius characters on either side, on a single line
+func Snippet(text, term string, radius int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	needle := []rune(strings.ToLower(term))
+	at := indexRunes(lower, needle)
+	if at < 0 {
+		at = 0
+	}
+
+	start, end := at-radius, at+len(needle)+radius
+	prefix, suffix := "…", "…"
+
+	if start <= 0 {
+		start, prefix = 0, ""
+	}
+	if end >= len(runes) {
+		end, suffix = len(runes), ""
+	}
+
+	return prefix + strings.TrimSpace(string(runes[start:end])) + suffix
+}
+
+func indexRunes(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if string(haystack[i:i+len(needle)]) == string(needle) {
+			return i
+		}
+	}
+	return -1
+}
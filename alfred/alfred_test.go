@@ -0,0 +1,70 @@
+package alfred
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnippet(t *testing.T) {
+	text := "Whiteboard:\n  Q3 roadmap — ship the IMPORTER, then hire two engineers for the platform team"
+
+	assert.Equal(t, "…ship the IMPORTER, then hir…", Snippet(text, "importer", 10))
+	assert.Equal(t, "Whiteboard: Q…", Snippet(text, "whiteboard", 3))
+	assert.Equal(t, "…platform team", Snippet(text, "team", 9))
+	assert.Equal(t, "short", Snippet("short", "missing", 10))
+}
+
+func TestAlfredAttachmentXML(t *testing.T) {
+	matches := []*db.AttachmentMatch{{
+		Note:     &db.Result{ID: "ABC-123", Title: "receipts"},
+		Filename: "scan.pdf",
+		Text:     "Total <incl. tax> & tip: $42",
+	}}
+
+	xml := AlfredAttachmentXML(matches, "tax")
+
+	assert.True(t, strings.Contains(xml, "<title>Receipts</title>"), xml)
+	assert.True(t, strings.Contains(xml, "<subtitle>scan.pdf: Total &lt;incl. tax&gt; &amp; tip: $42</subtitle>"), xml)
+	assert.True(t, strings.Contains(xml, "<arg>ABC-123</arg>"), xml)
+}
+
+func TestAlfredAttachmentXMLGroupsByNote(t *testing.T) {
+	note := &db.Result{ID: "ABC-123", Title: "receipts"}
+	matches := []*db.AttachmentMatch{
+		{Note: note, Filename: "scan.pdf", Text: "tax: $4"},
+		{Note: &db.Result{ID: "DEF-456", Title: "taxes"}, Filename: "w2.png", Text: "tax year"},
+		{Note: note, Filename: "photo.jpg", Text: "sales tax"},
+	}
+
+	xml := AlfredAttachmentXML(matches, "tax")
+
+	assert.Equal(t, 2, strings.Count(xml, "<item "), xml)
+	assert.Equal(t, 1, strings.Count(xml, "<arg>ABC-123</arg>"), xml)
+	assert.True(t, strings.Contains(xml, "<subtitle>scan.pdf, photo.jpg: tax: $4</subtitle>"), xml)
+	assert.Less(t, strings.Index(xml, "ABC-123"), strings.Index(xml, "DEF-456"), xml)
+}
+
+func TestAlfredOpenXMLLocksEncryptedNotes(t *testing.T) {
+	xml := AlfredOpenXML(db.Results{
+		{ID: "A", Title: "plain"},
+		{ID: "B", Title: "secret", Encrypted: true},
+	}, false)
+
+	assert.True(t, strings.Contains(xml, "<title>Plain</title>"), xml)
+	assert.True(t, strings.Contains(xml, "<title>"+LockIndicator+"Secret</title>"), xml)
+}
+
+func TestAlfredCreateXMLEmitsVersionedArg(t *testing.T) {
+	xml := AlfredCreateXML("v1.2 notes")
+
+	arg := ext.NewArg(ext.Create, ext.Payload{Title: "V1.2 Notes"}).String()
+	assert.True(t, strings.Contains(xml, "<arg>"+arg+"</arg>"), xml)
+
+	parsed, err := ext.ParseArg(arg)
+	assert.NoError(t, err)
+	assert.Equal(t, "V1.2 Notes", parsed.Payload.Title)
+}
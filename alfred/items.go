@@ -0,0 +1,33 @@
+package alfred
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// Item is a single result in Alfred's Script Filter JSON format
+type Item struct {
+	UID          string `json:"uid,omitempty"`
+	Title        string `json:"title"`
+	Subtitle     string `json:"subtitle,omitempty"`
+	Arg          string `json:"arg,omitempty"`
+	Autocomplete string `json:"autocomplete,omitempty"`
+	Valid        bool   `json:"valid"`
+}
+
+// AlfredJSON renders the items in Alfred's Script Filter JSON format
+func AlfredJSON(items []*Item) (string, error) {
+	if items == nil {
+		items = make([]*Item, 0)
+	}
+
+	data, err := json.Marshal(struct {
+		Items []*Item `json:"items"`
+	}{items})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,267 @@
+package bearurl
+
+// Action is an x-callback-url action's parameters
+type Action interface {
+	URL() *URL
+	String() string
+}
+
+// Mode is how add-text and add-file add to a note
+type Mode string
+
+const (
+	Append     Mode = "append"
+	Prepend    Mode = "prepend"
+	Replace    Mode = "replace"
+	ReplaceAll Mode = "replace_all"
+)
+
+// Window configures how Bear shows a note. Unset fields are left to Bear's defaults.
+type Window struct {
+	// OpenNote displays the note in Bear
+	OpenNote bool
+	// NewWindow opens the note in an external window
+	NewWindow bool
+	// ShowWindow brings Bear's main window to the front
+	ShowWindow bool
+	// Edit places the cursor in the note's editor
+	Edit bool
+	// Background keeps Bear in the background, overriding OpenNote and ShowWindow
+	Background bool
+}
+
+func (w Window) apply(u *URL) *URL {
+	if w.Background {
+		return u.
+			Set("open_note", "no").
+			SetBool("new_window", w.NewWindow).
+			Set("show_window", "no").
+			SetBool("edit", w.Edit)
+	}
+
+	return u.
+		SetBool("open_note", w.OpenNote).
+		SetBool("new_window", w.NewWindow).
+		SetBool("show_window", w.ShowWindow).
+		SetBool("edit", w.Edit)
+}
+
+// Create creates a new note
+type Create struct {
+	Title     string
+	Text      string
+	Tags      []string
+	Clipboard bool
+	Pin       bool
+	Timestamp bool
+	Window
+}
+
+func (c Create) URL() *URL {
+	u := New("create").
+		Set("title", c.Title).
+		Set("text", c.Text).
+		SetList("tags", c.Tags).
+		SetBool("clipboard", c.Clipboard).
+		SetBool("pin", c.Pin).
+		SetBool("timestamp", c.Timestamp)
+
+	return c.Window.apply(u)
+}
+
+func (c Create) String() string {
+	return c.URL().String()
+}
+
+// AddText adds text to the note with the given ID (or title), optionally beneath a header
+type AddText struct {
+	ID        string
+	Title     string
+	Text      string
+	Header    string
+	Mode      Mode
+	NewLine   bool
+	Tags      []string
+	Clipboard bool
+	Timestamp bool
+	Window
+}
+
+func (a AddText) URL() *URL {
+	u := New("add-text").
+		Set("id", a.ID).
+		Set("title", a.Title).
+		Set("text", a.Text).
+		Set("header", a.Header).
+		Set("mode", string(a.Mode)).
+		SetBool("new_line", a.NewLine).
+		SetList("tags", a.Tags).
+		SetBool("clipboard", a.Clipboard).
+		SetBool("timestamp", a.Timestamp)
+
+	return a.Window.apply(u)
+}
+
+func (a AddText) String() string {
+	return a.URL().String()
+}
+
+// OpenNote opens the note with the given ID (or title), optionally scrolled to a header
+type OpenNote struct {
+	ID             string
+	Title          string
+	Header         string
+	ExcludeTrashed bool
+	Search         string
+	Pin            bool
+	Window
+}
+
+func (o OpenNote) URL() *URL {
+	u := New("open-note").
+		Set("id", o.ID).
+		Set("title", o.Title).
+		Set("header", o.Header).
+		SetBool("exclude_trashed", o.ExcludeTrashed).
+		Set("search", o.Search).
+		SetBool("pin", o.Pin)
+
+	return o.Window.apply(u)
+}
+
+func (o OpenNote) String() string {
+	return o.URL().String()
+}
+
+// OpenTag shows the notes with the given tags
+type OpenTag struct {
+	Names []string
+}
+
+func (o OpenTag) URL() *URL {
+	return New("open-tag").SetList("name", o.Names)
+}
+
+func (o OpenTag) String() string {
+	return o.URL().String()
+}
+
+// Search searches for the term, optionally within a tag
+type Search struct {
+	Term       string
+	Tag        string
+	ShowWindow bool
+}
+
+func (s Search) URL() *URL {
+	return New("search").
+		Set("term", s.Term).
+		Set("tag", s.Tag).
+		SetBool("show_window", s.ShowWindow)
+}
+
+func (s Search) String() string {
+	return s.URL().String()
+}
+
+// AddFile adds a file (base64-encoded) to the note with the given ID (or title)
+type AddFile struct {
+	ID       string
+	Title    string
+	File     string
+	Filename string
+	Header   string
+	Mode     Mode
+	Window
+}
+
+func (a AddFile) URL() *URL {
+	u := New("add-file").
+		Set("id", a.ID).
+		Set("title", a.Title).
+		Set("file", a.File).
+		Set("filename", a.Filename).
+		Set("header", a.Header).
+		Set("mode", string(a.Mode))
+
+	return a.Window.apply(u)
+}
+
+func (a AddFile) String() string {
+	return a.URL().String()
+}
+
+// GrabURL creates a new note with the contents of a web page
+type GrabURL struct {
+	// Page is the web page's URL
+	Page string
+	Tags []string
+	Pin  bool
+	Wait bool
+}
+
+func (g GrabURL) URL() *URL {
+	return New("grab-url").
+		Set("url", g.Page).
+		SetList("tags", g.Tags).
+		SetBool("pin", g.Pin).
+		SetBool("wait", g.Wait)
+}
+
+func (g GrabURL) String() string {
+	return g.URL().String()
+}
+
+// Archive archives the note with the given ID
+type Archive struct {
+	ID         string
+	Search     string
+	ShowWindow bool
+}
+
+func (a Archive) URL() *URL {
+	return New("archive").
+		Set("id", a.ID).
+		Set("search", a.Search).
+		SetBool("show_window", a.ShowWindow)
+}
+
+func (a Archive) String() string {
+	return a.URL().String()
+}
+
+// Trash moves the note with the given ID to the trash
+type Trash struct {
+	ID         string
+	Search     string
+	ShowWindow bool
+}
+
+func (t Trash) URL() *URL {
+	return New("trash").
+		Set("id", t.ID).
+		Set("search", t.Search).
+		SetBool("show_window", t.ShowWindow)
+}
+
+func (t Trash) String() string {
+	return t.URL().String()
+}
+
+// RenameTag renames a tag (and the tags nested beneath it)
+type RenameTag struct {
+	Name       string
+	NewName    string
+	ShowWindow bool
+}
+
+func (r RenameTag) URL() *URL {
+	return New("rename-tag").
+		Set("name", r.Name).
+		Set("new_name", r.NewName).
+		SetBool("show_window", r.ShowWindow)
+}
+
+func (r RenameTag) String() string {
+	return r.URL().String()
+}
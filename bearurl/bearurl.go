@@ -0,0 +1,126 @@
+package bearurl
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Prefix is the start of every Bear x-callback-url
+	Prefix = "bear://x-callback-url/"
+
+	yes = "yes"
+)
+
+// URL is a Bear x-callback-url: an action (e.g. create) and its parameters, in order
+type URL struct {
+	Action string
+	Params []Param
+}
+
+// Param is a single x-callback-url parameter
+type Param struct {
+	Key   string
+	Value string
+}
+
+// New creates a URL for the action
+func New(action string) *URL {
+	return &URL{Action: action, Params: make([]Param, 0)}
+}
+
+// Set appends a parameter, unless its value is empty
+func (u *URL) Set(key, value string) *URL {
+	if value != "" {
+		u.Params = append(u.Params, Param{Key: key, Value: value})
+	}
+	return u
+}
+
+// SetBool appends a parameter with the value `yes` if b is true
+func (u *URL) SetBool(key string, b bool) *URL {
+	if b {
+		u.Set(key, yes)
+	}
+	return u
+}
+
+// SetList appends a parameter whose value is the comma-separated values, unless there aren't any
+func (u *URL) SetList(key string, values []string) *URL {
+	return u.Set(key, strings.Join(values, ","))
+}
+
+// Get returns the value of the first parameter with the key, or the empty string if there isn't one
+func (u *URL) Get(key string) string {
+	for _, p := range u.Params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// String renders the URL, percent-encoding its parameters
+func (u *URL) String() string {
+	b := strings.Builder{}
+	b.WriteString(Prefix)
+	b.WriteString(u.Action)
+
+	for i, p := range u.Params {
+		if i == 0 {
+			b.WriteString("?")
+		} else {
+			b.WriteString("&")
+		}
+		b.WriteString(Encode(p.Key))
+		b.WriteString("=")
+		b.WriteString(Encode(p.Value))
+	}
+
+	return b.String()
+}
+
+// Parse parses a Bear x-callback-url, preserving the order of its parameters
+func Parse(s string) (*URL, error) {
+	if !strings.HasPrefix(s, Prefix) {
+		return nil, errors.Errorf("not a bear x-callback-url: %s", s)
+	}
+
+	action, query := strings.TrimPrefix(s, Prefix), ""
+	if i := strings.Index(action, "?"); i >= 0 {
+		action, query = action[:i], action[i+1:]
+	}
+
+	u := New(action)
+
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, value := pair, ""
+		if i := strings.Index(pair, "="); i >= 0 {
+			key, value = pair[:i], pair[i+1:]
+		}
+
+		var err error
+		if key, err = url.QueryUnescape(key); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		u.Params = append(u.Params, Param{Key: key, Value: value})
+	}
+
+	return u, nil
+}
+
+// Encode percent-encodes a parameter value. Bear doesn't decode `+` as a space, so spaces are
+// encoded as %20.
+func Encode(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
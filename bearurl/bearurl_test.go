@@ -0,0 +1,76 @@
+package bearurl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, "a%20b%0A-%20%5B%20%5D%20c%26d%2Ce", Encode("a b\n- [ ] c&d,e"))
+	assert.Equal(t, "1%2B1%3D2", Encode("1+1=2"))
+	assert.Equal(t, "caf%C3%A9%20%F0%9F%93%85", Encode("café 📅"))
+}
+
+func TestString(t *testing.T) {
+	create := Create{Title: "My Note", Text: "- [ ] a & b", Tags: []string{"work", "work/q1"}, Window: Window{OpenNote: true, Edit: true}}
+
+	assert.Equal(t, "bear://x-callback-url/create?title=My%20Note&text=-%20%5B%20%5D%20a%20%26%20b&tags=work%2Cwork%2Fq1&open_note=yes&edit=yes", create.String())
+	assert.Equal(t, "bear://x-callback-url/open-tag?name=work", OpenTag{Names: []string{"work"}}.String())
+	assert.Equal(t, "bear://x-callback-url/archive", Archive{}.String())
+	assert.Equal(t, "bear://x-callback-url/add-text?id=1&text=t&open_note=no&show_window=no", AddText{ID: "1", Text: "t", Window: Window{OpenNote: true, Background: true}}.String())
+}
+
+func TestRoundTrip(t *testing.T) {
+	actions := []Action{
+		Create{Title: "Title & more", Text: "line 1\nline 2 + 3%", Tags: []string{"a b", "c/d"}, Clipboard: true, Pin: true, Timestamp: true, Window: Window{OpenNote: true, NewWindow: true, ShowWindow: true, Edit: true}},
+		AddText{ID: "ABC-123", Text: "appended", Header: "## Log", Mode: Append, NewLine: true, Tags: []string{"x"}},
+		AddText{Title: "Inbox", Text: "first", Mode: Prepend, Window: Window{Background: true}},
+		AddText{ID: "ABC-123", Text: "everything", Mode: ReplaceAll, Timestamp: true},
+		OpenNote{ID: "ABC-123", Header: "Heading = #1", ExcludeTrashed: true, Window: Window{ShowWindow: true}},
+		OpenTag{Names: []string{"work", "home/errands"}},
+		Search{Term: "coffee & tea?", Tag: "drinks", ShowWindow: true},
+		AddFile{ID: "ABC-123", File: "aGVsbG8=", Filename: "hello.txt", Mode: Append},
+		GrabURL{Page: "https://example.com/a?b=c&d=e#f", Tags: []string{"web"}, Pin: true, Wait: true},
+		Archive{ID: "ABC-123", ShowWindow: true},
+		Trash{Search: "old stuff"},
+		RenameTag{Name: "todo", NewName: "tasks/open"},
+	}
+
+	for _, action := range actions {
+		parsed, err := Parse(action.String())
+		assert.NoError(t, err, action.String())
+		assert.Equal(t, action.URL(), parsed, action.String())
+		assert.Equal(t, action.String(), parsed.String())
+	}
+}
+
+func TestParse(t *testing.T) {
+	u, err := Parse("bear://x-callback-url/create?title=a+b&text=c%20d&tags=x%2Cy&empty=")
+	assert.NoError(t, err)
+	assert.Equal(t, "create", u.Action)
+	assert.Equal(t, "a b", u.Get("title"))
+	assert.Equal(t, "c d", u.Get("text"))
+	assert.Equal(t, "x,y", u.Get("tags"))
+	assert.Equal(t, "", u.Get("missing"))
+
+	u, err = Parse("bear://x-callback-url/open-tag")
+	assert.NoError(t, err)
+	assert.Equal(t, "open-tag", u.Action)
+	assert.Empty(t, u.Params)
+
+	_, err = Parse("https://example.com")
+	assert.Error(t, err)
+
+	_, err = Parse("bear://x-callback-url/create?title=%zz")
+	assert.Error(t, err)
+}
+
+func TestAddTextModes(t *testing.T) {
+	for _, mode := range []Mode{Append, Prepend, Replace, ReplaceAll} {
+		u := AddText{ID: "1", Text: "t", Mode: mode}.URL()
+		assert.Equal(t, string(mode), u.Get("mode"))
+	}
+
+	assert.Equal(t, "", AddText{ID: "1"}.URL().Get("mode"))
+}
@@ -0,0 +1,206 @@
+package attachments
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optSort    string
+	optRollup  string
+	optFormat  string
+	optOrphans bool
+	optRoot    string
+	optLimit   int
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attachments",
+		Short: "Report on attachments",
+		Long:  "List every attachment with its size, dimensions, type, dates and sync state, roll up their sizes per note or tag, or find files on disk that Bear no longer references",
+		Args:  cobra.NoArgs,
+		RunE:  runner,
+	}
+
+	cmd.Flags().StringVar(&optSort, "sort", "size", "sort by: size, name, note, created or inserted")
+	cmd.Flags().StringVar(&optRollup, "rollup", "", "total the sizes per note or per tag")
+	cmd.Flags().StringVar(&optFormat, "format", "text", "output format (text, csv, json, alfred)")
+	cmd.Flags().BoolVar(&optOrphans, "orphans", false, "list files on disk that aren't in the database")
+	cmd.Flags().StringVar(&optRoot, "root", "", "Bear's data directory, for --orphans (default: ~/"+db.DataDirectory+")")
+	cmd.Flags().IntVar(&optLimit, "limit", 0, "only show this many rows (default: all)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	attachments, err := bearDB.AttachmentInventory()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if optOrphans {
+		root := optRoot
+		if root == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			root = path.Join(home, db.DataDirectory)
+		}
+
+		orphans, err := findOrphans(root, attachments)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return writeOrphans(os.Stdout, util.Limit(orphans, optLimit), optFormat)
+	}
+
+	if optRollup != "" {
+		totals, err := rollup(attachments, optRollup)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return writeTotals(os.Stdout, util.Limit(totals, optLimit), optFormat)
+	}
+
+	if err := sortAttachments(attachments, optSort); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return writeAttachments(os.Stdout, util.Limit(attachments, optLimit), optFormat)
+}
+
+func writeAttachments(w io.Writer, attachments []*db.AttachmentInfo, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "SIZE\tFILE\tNOTE\tDIMENSIONS\tCREATED\tSTATE")
+		for _, a := range attachments {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", util.HumanSize(a.Size), a.Filename, a.NoteTitle, dimensions(a), a.Created, syncState(a))
+		}
+		return errors.WithStack(tw.Flush())
+	case "csv":
+		rows := [][]string{{"Note ID", "Note Title", "Tags", "File ID", "Filename", "Extension", "Size", "Width", "Height", "Created", "Inserted", "Downloaded", "Uploaded", "Trashed"}}
+		for _, a := range attachments {
+			rows = append(rows, []string{
+				a.NoteID, a.NoteTitle, joinTags(a.Tags), a.FolderUUID, a.Filename, a.Extension,
+				strconv.FormatInt(a.Size, 10), strconv.Itoa(a.Width), strconv.Itoa(a.Height), a.Created, a.Inserted,
+				strconv.FormatBool(a.Downloaded), strconv.FormatBool(a.Uploaded), strconv.FormatBool(a.Trashed),
+			})
+		}
+		return errors.WithStack(csv.NewWriter(w).WriteAll(rows))
+	case "json":
+		return errors.WithStack(json.NewEncoder(w).Encode(attachments))
+	case "alfred":
+		items := make([]*alfred.Item, 0, len(attachments))
+		for _, a := range attachments {
+			items = append(items, &alfred.Item{
+				UID:      a.FolderUUID,
+				Title:    a.Filename,
+				Subtitle: fmt.Sprintf("%s · %s · %s", util.HumanSize(a.Size), a.NoteTitle, syncState(a)),
+				Arg:      a.NoteID,
+				Valid:    a.NoteID != "",
+			})
+		}
+		return writeAlfred(w, items)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeTotals(w io.Writer, totals []*Total, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "SIZE\tFILES\tNAME")
+		for _, t := range totals {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", util.HumanSize(t.Size), t.Count, t.Name)
+		}
+		return errors.WithStack(tw.Flush())
+	case "csv":
+		rows := [][]string{{"Name", "ID", "Files", "Size"}}
+		for _, t := range totals {
+			rows = append(rows, []string{t.Name, t.ID, strconv.Itoa(t.Count), strconv.FormatInt(t.Size, 10)})
+		}
+		return errors.WithStack(csv.NewWriter(w).WriteAll(rows))
+	case "json":
+		return errors.WithStack(json.NewEncoder(w).Encode(totals))
+	case "alfred":
+		items := make([]*alfred.Item, 0, len(totals))
+		for _, t := range totals {
+			items = append(items, &alfred.Item{
+				Title:    t.Name,
+				Subtitle: fmt.Sprintf("%s in %s", util.HumanSize(t.Size), util.Pluralize(t.Count, "file")),
+				Arg:      t.ID,
+				Valid:    t.ID != "",
+			})
+		}
+		return writeAlfred(w, items)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeOrphans(w io.Writer, orphans []*Orphan, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "SIZE\tPATH")
+		for _, o := range orphans {
+			fmt.Fprintf(tw, "%s\t%s\n", util.HumanSize(o.Size), o.Path)
+		}
+		return errors.WithStack(tw.Flush())
+	case "csv":
+		rows := [][]string{{"Path", "Size"}}
+		for _, o := range orphans {
+			rows = append(rows, []string{o.Path, strconv.FormatInt(o.Size, 10)})
+		}
+		return errors.WithStack(csv.NewWriter(w).WriteAll(rows))
+	case "json":
+		return errors.WithStack(json.NewEncoder(w).Encode(orphans))
+	case "alfred":
+		items := make([]*alfred.Item, 0, len(orphans))
+		for _, o := range orphans {
+			items = append(items, &alfred.Item{
+				Title:    path.Base(o.Path),
+				Subtitle: fmt.Sprintf("%s · %s", util.HumanSize(o.Size), o.Path),
+				Arg:      o.Path,
+				Valid:    true,
+			})
+		}
+		return writeAlfred(w, items)
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+func writeAlfred(w io.Writer, items []*alfred.Item) error {
+	output, err := alfred.AlfredJSON(items)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = fmt.Fprintln(w, output)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,190 @@
+package attachments
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+)
+
+const untagged = "(untagged)"
+
+// Total is the combined size of the attachments of a note or tag
+type Total struct {
+	Name string `json:"name"`
+	// ID is the note's ID, for a per-note total
+	ID    string `json:"id,omitempty"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// Orphan is a file in Bear's data directory that no attachment refers to
+type Orphan struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// sortAttachments sorts by size or date (largest or newest first), or by name or note
+func sortAttachments(attachments []*db.AttachmentInfo, by string) error {
+	var less func(a, b *db.AttachmentInfo) bool
+
+	switch by {
+	case "size":
+		less = func(a, b *db.AttachmentInfo) bool { return a.Size > b.Size }
+	case "name":
+		less = func(a, b *db.AttachmentInfo) bool { return strings.ToLower(a.Filename) < strings.ToLower(b.Filename) }
+	case "note":
+		less = func(a, b *db.AttachmentInfo) bool { return strings.ToLower(a.NoteTitle) < strings.ToLower(b.NoteTitle) }
+	case "created":
+		less = func(a, b *db.AttachmentInfo) bool { return a.Created > b.Created }
+	case "inserted":
+		less = func(a, b *db.AttachmentInfo) bool { return a.Inserted > b.Inserted }
+	default:
+		return fmt.Errorf("unknown sort: %s", by)
+	}
+
+	sort.SliceStable(attachments, func(i, j int) bool {
+		return less(attachments[i], attachments[j])
+	})
+
+	return nil
+}
+
+// rollup totals the attachments per note, or per tag (where an attachment counts towards each of
+// its note's tags, including the parents of nested tags), largest first
+func rollup(attachments []*db.AttachmentInfo, by string) ([]*Total, error) {
+	totals := make(map[string]*Total)
+
+	add := func(key, name, id string, a *db.AttachmentInfo) {
+		t, ok := totals[key]
+		if !ok {
+			t = &Total{Name: name, ID: id}
+			totals[key] = t
+		}
+		t.Count++
+		t.Size += a.Size
+	}
+
+	for _, a := range attachments {
+		switch by {
+		case "note":
+			add(a.NoteID, a.NoteTitle, a.NoteID, a)
+		case "tag":
+			if len(a.Tags) == 0 {
+				add(untagged, untagged, "", a)
+			}
+			for _, tag := range a.Tags {
+				add(tag, tag, "", a)
+			}
+		default:
+			return nil, fmt.Errorf("unknown rollup: %s", by)
+		}
+	}
+
+	sorted := make([]*Total, 0, len(totals))
+	for _, t := range totals {
+		sorted = append(sorted, t)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Size != sorted[j].Size {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	return sorted, nil
+}
+
+// findOrphans walks the attachment folders within Bear's data directory, returning the files that
+// don't belong to any attachment in the database, largest first
+func findOrphans(root string, attachments []*db.AttachmentInfo) ([]*Orphan, error) {
+	known := make(map[string]bool)
+	for _, a := range attachments {
+		known[path.Join(a.FolderUUID, a.Filename)] = true
+	}
+
+	orphans := make([]*Orphan, 0)
+
+	for _, dir := range []string{"Note Images", "Note Files"} {
+		base := filepath.Join(root, "Local Files", dir)
+
+		err := filepath.WalkDir(base, func(file string, d fs.DirEntry, err error) error {
+			if os.IsNotExist(err) && file == base {
+				return filepath.SkipDir
+			} else if err != nil {
+				return err
+			}
+
+			if d.IsDir() || strings.HasPrefix(d.Name(), ".") {
+				return nil
+			}
+
+			// match on <uuid>/<filename> alone, so that a file in the "wrong" folder isn't reported
+			rel, err := filepath.Rel(base, file)
+			if err != nil {
+				return err
+			}
+
+			if known[filepath.ToSlash(rel)] {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			orphans = append(orphans, &Orphan{Path: file, Size: info.Size()})
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	sort.SliceStable(orphans, func(i, j int) bool {
+		return orphans[i].Size > orphans[j].Size
+	})
+
+	return orphans, nil
+}
+
+func dimensions(a *db.AttachmentInfo) string {
+	if a.Width == 0 || a.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", a.Width, a.Height)
+}
+
+// syncState describes whether the attachment has been downloaded to and uploaded from this device
+func syncState(a *db.AttachmentInfo) string {
+	states := make([]string, 0, 3)
+
+	if !a.Downloaded {
+		states = append(states, "not downloaded")
+	}
+	if !a.Uploaded {
+		states = append(states, "not uploaded")
+	}
+	if a.Trashed {
+		states = append(states, "trashed")
+	}
+	if len(states) == 0 {
+		return "synced"
+	}
+
+	return strings.Join(states, ", ")
+}
+
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
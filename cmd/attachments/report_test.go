@@ -0,0 +1,107 @@
+package attachments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAttachments() []*db.AttachmentInfo {
+	return []*db.AttachmentInfo{
+		{Attachment: db.Attachment{NoteTitle: "Trip", FolderUUID: "u1", Filename: "beach.jpg"}, NoteID: "n1", Tags: []string{"travel"}, Size: 3000, Created: "2024-01-02 10:00:00"},
+		{Attachment: db.Attachment{NoteTitle: "Taxes", FolderUUID: "u2", Filename: "W2.pdf"}, NoteID: "n2", Tags: []string{"home", "home/finance"}, Size: 5000, Created: "2024-03-01 09:00:00"},
+		{Attachment: db.Attachment{NoteTitle: "Trip", FolderUUID: "u3", Filename: "map.png"}, NoteID: "n1", Tags: []string{"travel"}, Size: 1000, Created: "2024-02-01 08:00:00"},
+		{Attachment: db.Attachment{NoteTitle: "Scratch", FolderUUID: "u4", Filename: "notes.txt"}, NoteID: "n3", Size: 10},
+	}
+}
+
+func filenames(attachments []*db.AttachmentInfo) []string {
+	names := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		names = append(names, a.Filename)
+	}
+	return names
+}
+
+func TestSortAttachments(t *testing.T) {
+	attachments := testAttachments()
+
+	assert.NoError(t, sortAttachments(attachments, "size"))
+	assert.Equal(t, []string{"W2.pdf", "beach.jpg", "map.png", "notes.txt"}, filenames(attachments))
+
+	assert.NoError(t, sortAttachments(attachments, "name"))
+	assert.Equal(t, []string{"beach.jpg", "map.png", "notes.txt", "W2.pdf"}, filenames(attachments))
+
+	assert.NoError(t, sortAttachments(attachments, "created"))
+	assert.Equal(t, []string{"W2.pdf", "map.png", "beach.jpg", "notes.txt"}, filenames(attachments))
+
+	assert.Error(t, sortAttachments(attachments, "color"))
+}
+
+func TestRollupByNote(t *testing.T) {
+	totals, err := rollup(testAttachments(), "note")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Total{
+		{Name: "Taxes", ID: "n2", Count: 1, Size: 5000},
+		{Name: "Trip", ID: "n1", Count: 2, Size: 4000},
+		{Name: "Scratch", ID: "n3", Count: 1, Size: 10},
+	}, totals)
+}
+
+func TestRollupByTag(t *testing.T) {
+	totals, err := rollup(testAttachments(), "tag")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Total{
+		{Name: "home", Count: 1, Size: 5000},
+		{Name: "home/finance", Count: 1, Size: 5000},
+		{Name: "travel", Count: 2, Size: 4000},
+		{Name: untagged, Count: 1, Size: 10},
+	}, totals)
+
+	_, err = rollup(testAttachments(), "folder")
+	assert.Error(t, err)
+}
+
+func TestFindOrphans(t *testing.T) {
+	root := t.TempDir()
+
+	write := func(rel string, size int) {
+		file := filepath.Join(root, "Local Files", rel)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.NoError(t, os.WriteFile(file, make([]byte, size), 0644))
+	}
+
+	write("Note Images/u1/beach.jpg", 3)
+	write("Note Images/u3/map.png", 1)
+	write("Note Images/u9/old.jpg", 20)
+	write("Note Files/u2/W2.pdf", 5)
+	write("Note Files/u8/stale.zip", 40)
+	write("Note Files/u8/.DS_Store", 1)
+	// in the other folder than export would expect, but still referenced
+	write("Note Images/u4/notes.txt", 1)
+
+	orphans, err := findOrphans(root, testAttachments())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*Orphan{
+		{Path: filepath.Join(root, "Local Files", "Note Files", "u8", "stale.zip"), Size: 40},
+		{Path: filepath.Join(root, "Local Files", "Note Images", "u9", "old.jpg"), Size: 20},
+	}, orphans)
+}
+
+func TestFindOrphansWithoutLocalFiles(t *testing.T) {
+	orphans, err := findOrphans(t.TempDir(), testAttachments())
+
+	assert.NoError(t, err)
+	assert.Empty(t, orphans)
+}
+
+func TestSyncState(t *testing.T) {
+	assert.Equal(t, "synced", syncState(&db.AttachmentInfo{Downloaded: true, Uploaded: true}))
+	assert.Equal(t, "not downloaded, trashed", syncState(&db.AttachmentInfo{Uploaded: true, Trashed: true}))
+}
@@ -0,0 +1,224 @@
+package capture
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/daily"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultInbox is the note captured into when neither --to nor capture.to are set
+	DefaultInbox = "Inbox"
+	// Journal is the --to value for today's daily note
+	Journal = "journal"
+	// DefaultHeadingLevel is the level of the heading that a new note's text is added beneath
+	DefaultHeadingLevel = 2
+)
+
+var (
+	noteIDRegex = regexp.MustCompile(`(?i)^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}(-[0-9a-f-]+)?$`)
+
+	optTo        string
+	optHeading   string
+	optLevel     int
+	optMode      string
+	optClipboard bool
+	optTimestamp bool
+	optTags      []string
+	optOpen      bool
+)
+
+// Target is the note being captured into
+type Target struct {
+	// ID is the note's ID, or empty if the note doesn't exist yet
+	ID    string
+	Title string
+	// Tags are added to the note if it has to be created
+	Tags []string
+}
+
+// Options configures how text is captured
+type Options struct {
+	Heading string
+	// HeadingLevel is the level of the heading when the note is created (e.g. 2 for `## Heading`)
+	HeadingLevel int
+	Mode         bearurl.Mode
+	Clipboard    bool
+	Timestamp    bool
+	Tags         []string
+	Open         bool
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "capture [text]",
+		Short: "Capture text into a note",
+		Long: `Generate a Bear URL that adds text to a note (by default, your inbox) without opening Bear, creating the note if it doesn't exist.
+
+The text is read from the arguments, or from stdin if there aren't any (or from the clipboard, with --clipboard).`,
+		Args: cobra.ArbitraryArgs,
+		RunE: runner,
+	}
+
+	cmd.Flags().StringVar(&optTo, "to", "", `note to capture into: a title, a note ID, or "journal" for today's daily note (default: capture.to from config, else Inbox)`)
+	cmd.Flags().StringVar(&optHeading, "heading", "", "add the text beneath this heading (default: capture.heading from config)")
+	cmd.Flags().IntVar(&optLevel, "heading-level", DefaultHeadingLevel, "level of the heading if the note has to be created (capture.heading_level from config, if set)")
+	cmd.Flags().StringVar(&optMode, "mode", "append", "append or prepend")
+	cmd.Flags().BoolVar(&optClipboard, "clipboard", false, "capture the clipboard's contents")
+	cmd.Flags().BoolVar(&optTimestamp, "timestamp", false, "prefix the text with the current date and time")
+	cmd.Flags().StringSliceVar(&optTags, "tags", nil, "tags to add to the note")
+	cmd.Flags().BoolVar(&optOpen, "open", false, "open the note in Bear (default: stay in the background)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	to := optTo
+	if to == "" {
+		to = cfg.Capture.To
+	}
+	if to == "" {
+		to = DefaultInbox
+	}
+
+	heading := optHeading
+	if !cmd.Flags().Changed("heading") {
+		heading = cfg.Capture.Heading
+	}
+
+	level := optLevel
+	if !cmd.Flags().Changed("heading-level") && cfg.Capture.HeadingLevel > 0 {
+		level = cfg.Capture.HeadingLevel
+	}
+	if level < 1 || level > 6 {
+		return fmt.Errorf("heading level must be between 1 and 6: %d", level)
+	}
+
+	mode := bearurl.Mode(optMode)
+	if mode != bearurl.Append && mode != bearurl.Prepend {
+		return fmt.Errorf("unknown mode: %s", optMode)
+	}
+
+	text := strings.Join(args, " ")
+	if text == "" && !optClipboard {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		text = strings.TrimRight(string(data), "\n")
+	}
+
+	if text == "" && !optClipboard {
+		return fmt.Errorf("nothing to capture")
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	target, err := resolveTarget(cfg, to, time.Now(), func(title string) (db.Results, error) {
+		return bearDB.QueryTitles(title, true)
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Println(captureURL(target, text, &Options{
+		Heading:      heading,
+		HeadingLevel: level,
+		Mode:         mode,
+		Clipboard:    optClipboard,
+		Timestamp:    optTimestamp,
+		Tags:         optTags,
+		Open:         optOpen,
+	}))
+
+	return nil
+}
+
+// resolveTarget finds the note that `to` refers to: today's daily note for `journal`, the note
+// with that ID, or the note with that title. If there isn't such a note, the target has no ID, and
+// if several notes have the title, it's an error.
+func resolveTarget(cfg *config.Config, to string, now time.Time, findTitle func(string) (db.Results, error)) (*Target, error) {
+	if noteIDRegex.MatchString(to) {
+		return &Target{ID: to}, nil
+	}
+
+	target := &Target{Title: to}
+
+	if strings.EqualFold(to, Journal) {
+		title, tag := daily.Note(cfg, now)
+		target.Title = title
+		if tag != "" {
+			target.Tags = []string{tag}
+		}
+	}
+
+	results, err := findTitle(target.Title)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(results) > 1 {
+		return nil, fmt.Errorf("found too many matches for %q", target.Title)
+	} else if len(results) == 1 {
+		target.ID = results[0].ID
+	}
+
+	return target, nil
+}
+
+// captureURL returns the Bear URL that adds the text to the target note, or creates the note
+// (with the text beneath a heading of HeadingLevel) if it doesn't exist
+func captureURL(target *Target, text string, opts *Options) string {
+	window := bearurl.Window{Background: !opts.Open, OpenNote: opts.Open}
+
+	if target.ID != "" {
+		return bearurl.AddText{
+			ID:        target.ID,
+			Text:      text,
+			Header:    opts.Heading,
+			Mode:      opts.Mode,
+			NewLine:   opts.Mode == bearurl.Append,
+			Tags:      opts.Tags,
+			Clipboard: opts.Clipboard,
+			Timestamp: opts.Timestamp,
+			Window:    window,
+		}.String()
+	}
+
+	body := text
+	if opts.Heading != "" {
+		level := opts.HeadingLevel
+		if level == 0 {
+			level = DefaultHeadingLevel
+		}
+		body = fmt.Sprintf("%s %s\n%s", strings.Repeat("#", level), opts.Heading, text)
+	}
+
+	return bearurl.Create{
+		Title:     target.Title,
+		Text:      body,
+		Tags:      append(append([]string{}, target.Tags...), opts.Tags...),
+		Clipboard: opts.Clipboard,
+		Timestamp: opts.Timestamp,
+		Window:    window,
+	}.String()
+}
@@ -0,0 +1,97 @@
+package capture
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func findTitle(titles map[string]string) func(string) (db.Results, error) {
+	return func(title string) (db.Results, error) {
+		if id, ok := titles[title]; ok {
+			return db.Results{{ID: id, Title: title}}, nil
+		}
+		return db.Results{}, nil
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	cfg := config.Default()
+	cfg.Journal.Tag = "captainslog"
+	cfg.Journal.AppendDate = true
+
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	find := findTitle(map[string]string{"Inbox": "INBOX-ID", "2024-03-01": "DAILY-ID"})
+
+	target, err := resolveTarget(cfg, "Inbox", now, find)
+	assert.NoError(t, err)
+	assert.Equal(t, &Target{ID: "INBOX-ID", Title: "Inbox"}, target)
+
+	target, err = resolveTarget(cfg, "journal", now, find)
+	assert.NoError(t, err)
+	assert.Equal(t, "DAILY-ID", target.ID)
+	assert.Equal(t, "2024-03-01", target.Title)
+
+	target, err = resolveTarget(cfg, "journal", now.AddDate(0, 0, 1), find)
+	assert.NoError(t, err)
+	assert.Equal(t, &Target{Title: "2024-03-02", Tags: []string{"captainslog/2024/03"}}, target)
+
+	target, err = resolveTarget(cfg, "Someday", now, find)
+	assert.NoError(t, err)
+	assert.Equal(t, &Target{Title: "Someday"}, target)
+
+	_, err = resolveTarget(cfg, "Notes", now, func(title string) (db.Results, error) {
+		return db.Results{{ID: "NOTES-1", Title: title}, {ID: "NOTES-2", Title: title}}, nil
+	})
+	assert.EqualError(t, err, `found too many matches for "Notes"`)
+
+	id := "9B2C8A0E-6D3F-4B5A-8E7C-1F2A3B4C5D6E-1234-0000ABCDEF"
+	target, err = resolveTarget(cfg, id, now, find)
+	assert.NoError(t, err)
+	assert.Equal(t, &Target{ID: id}, target)
+}
+
+func TestCaptureURLExistingNote(t *testing.T) {
+	target := &Target{ID: "INBOX-ID", Title: "Inbox"}
+	u, err := bearurl.Parse(captureURL(target, "buy milk", &Options{Heading: "Errands", Mode: bearurl.Append, Tags: []string{"home"}, Timestamp: true}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "add-text", u.Action)
+	assert.Equal(t, "INBOX-ID", u.Get("id"))
+	assert.Equal(t, "buy milk", u.Get("text"))
+	assert.Equal(t, "Errands", u.Get("header"))
+	assert.Equal(t, "append", u.Get("mode"))
+	assert.Equal(t, "yes", u.Get("new_line"))
+	assert.Equal(t, "home", u.Get("tags"))
+	assert.Equal(t, "yes", u.Get("timestamp"))
+	assert.Equal(t, "no", u.Get("open_note"))
+
+	u, err = bearurl.Parse(captureURL(target, "first", &Options{Mode: bearurl.Prepend, Open: true}))
+	assert.NoError(t, err)
+	assert.Equal(t, "prepend", u.Get("mode"))
+	assert.Equal(t, "", u.Get("new_line"))
+	assert.Equal(t, "yes", u.Get("open_note"))
+}
+
+func TestCaptureURLMissingNote(t *testing.T) {
+	target := &Target{Title: "2024-03-02", Tags: []string{"captainslog/2024/03"}}
+	u, err := bearurl.Parse(captureURL(target, "an idea", &Options{Heading: "Ideas", Mode: bearurl.Append, Tags: []string{"ideas"}}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "create", u.Action)
+	assert.Equal(t, "2024-03-02", u.Get("title"))
+	assert.Equal(t, "## Ideas\nan idea", u.Get("text"))
+	assert.Equal(t, "captainslog/2024/03,ideas", u.Get("tags"))
+	assert.Equal(t, []string{"captainslog/2024/03"}, target.Tags, "the target's tags aren't modified")
+}
+
+func TestCaptureURLHeadingLevel(t *testing.T) {
+	target := &Target{Title: "Inbox"}
+	u, err := bearurl.Parse(captureURL(target, "an idea", &Options{Heading: "Ideas", HeadingLevel: 3, Mode: bearurl.Append}))
+	assert.NoError(t, err)
+	assert.Equal(t, "### Ideas\nan idea", u.Get("text"))
+}
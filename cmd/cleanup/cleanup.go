@@ -2,26 +2,109 @@ package cleanup
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
-	"github.com/mnadel/freddiebear/db"
 	"github.com/mnadel/freddiebear/cmd/export"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var (
+	optApply     bool
+	optRoot      string
+	optOlderThan string
+)
+
 func New() *cobra.Command {
-	searchCmd := &cobra.Command{
+	cleanupCmd := &cobra.Command{
 		Use:   "cleanup",
 		Short: "Cleanup your export",
-		Long:  "Generate list of attachments that can be deleted",
+		Long: `Generate list of attachments that can be deleted, i.e. those of trashed notes.
+
+With --apply and --root (your export directory, or Bear's data directory), move them into a dated
+quarantine directory beneath the root, along with a manifest, so they can be restored.`,
+		Args: cobra.ExactArgs(0),
+		RunE: runner,
+	}
+
+	cleanupCmd.PersistentFlags().StringVar(&optRoot, "root", "", "directory containing the attachments' Local Files directory")
+	cleanupCmd.PersistentFlags().BoolVar(&optApply, "apply", false, "move (or, for purge, delete) the files rather than list them")
+
+	cleanupCmd.AddCommand(newRestoreCmd())
+	cleanupCmd.AddCommand(newPurgeCmd())
+
+	return cleanupCmd
+}
+
+func newRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <manifest>",
+		Short: "Restore quarantined attachments",
+		Long:  "Move the attachments recorded in a quarantine manifest back to where they came from",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifest, err := Restore(args[0])
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			for _, f := range manifest.Files {
+				fmt.Println(f.Path)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newPurgeCmd() *cobra.Command {
+	purgeCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Delete old quarantined attachments",
+		Long:  "Permanently delete the quarantine directories beneath --root that are older than --older-than",
 		Args:  cobra.ExactArgs(0),
-		RunE:  runner,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if optRoot == "" {
+				return fmt.Errorf("--root is required")
+			}
+
+			age, err := util.ParseAge(optOlderThan)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			expired, err := Expired(optRoot, time.Now().Add(-age))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			for _, manifest := range expired {
+				if optApply {
+					if err := Purge(optRoot, manifest); err != nil {
+						return errors.WithStack(err)
+					}
+				}
+				fmt.Println(filepath.Dir(manifest))
+			}
+
+			return nil
+		},
 	}
 
-	return searchCmd
+	purgeCmd.Flags().StringVar(&optOlderThan, "older-than", "30d", "age of quarantine directories to delete, e.g. 30d or 72h")
+
+	return purgeCmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
+	if optApply && optRoot == "" {
+		return fmt.Errorf("--apply requires --root")
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
@@ -33,9 +116,32 @@ func runner(cmd *cobra.Command, args []string) error {
 		return errors.WithStack(err)
 	}
 
+	files := make([]string, 0, len(attachments))
 	for _, attach := range attachments {
-		fmt.Println(export.BuildAttachmentFilename(attach))
+		files = append(files, export.BuildAttachmentFilename(attach))
+	}
+
+	if !optApply {
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		return nil
+	}
+
+	manifest, quarantined, err := Quarantine(optRoot, files, time.Now())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if manifest == "" {
+		fmt.Fprintln(os.Stderr, "nothing to clean up")
+		return nil
+	}
+
+	for _, f := range quarantined.Files {
+		fmt.Println(f.Path)
 	}
+	fmt.Fprintf(os.Stderr, "moved %d files; to undo: freddiebear cleanup restore %q\n", len(quarantined.Files), manifest)
 
 	return nil
 }
@@ -0,0 +1,251 @@
+package cleanup
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// QuarantineDirectory is where removed attachments are moved, relative to the root
+	QuarantineDirectory = ".freddiebear-quarantine"
+	// ManifestFile records what was moved into a quarantine directory
+	ManifestFile = "manifest.json"
+
+	stampFormat = "2006-01-02T150405"
+)
+
+// Manifest records the files moved into a quarantine directory, so that they can be restored
+type Manifest struct {
+	Created time.Time `json:"created"`
+	// Root is the directory the files were moved from
+	Root  string         `json:"root"`
+	Files []*Quarantined `json:"files"`
+}
+
+// Quarantined is a file moved into quarantine
+type Quarantined struct {
+	// Path is relative to both the root and the quarantine directory
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// Quarantine moves the files (relative to root) that exist into a new, dated quarantine directory
+// beneath the root, and writes its manifest. It returns the manifest's path, or an empty string if
+// none of the files exist. Files outside the root are refused.
+func Quarantine(root string, files []string, now time.Time) (string, *Manifest, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	dir := filepath.Join(root, QuarantineDirectory, now.Format(stampFormat))
+	manifest := &Manifest{Created: now, Root: root, Files: make([]*Quarantined, 0)}
+
+	// check every path before moving anything
+	for _, file := range files {
+		src, err := within(root, file)
+		if err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+
+		info, err := os.Lstat(src)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return "", nil, errors.WithStack(err)
+		} else if !info.Mode().IsRegular() {
+			return "", nil, errors.Errorf("not a regular file: %s", src)
+		}
+
+		manifest.Files = append(manifest.Files, &Quarantined{Path: filepath.ToSlash(filepath.Clean(file)), Size: info.Size()})
+	}
+
+	if len(manifest.Files) == 0 {
+		return "", manifest, nil
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		return "", nil, errors.Errorf("quarantine directory already exists: %s", dir)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	// write the manifest first, so that a partial move can still be restored
+	manifestPath := filepath.Join(dir, ManifestFile)
+	if err := writeManifest(manifestPath, manifest); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+
+	for _, f := range manifest.Files {
+		if err := move(filepath.Join(root, f.Path), filepath.Join(dir, f.Path)); err != nil {
+			return "", nil, errors.WithStack(err)
+		}
+	}
+
+	return manifestPath, manifest, nil
+}
+
+// Restore moves the files in a quarantine directory back to where they came from, then removes the
+// quarantine directory. Files that would overwrite an existing file, or land outside the root, are refused.
+func Restore(manifestPath string) (*Manifest, error) {
+	manifest, err := readManifest(manifestPath)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dir := filepath.Dir(manifestPath)
+
+	type pending struct{ src, dst string }
+	moves := make([]pending, 0, len(manifest.Files))
+
+	for _, f := range manifest.Files {
+		src, err := within(dir, f.Path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		dst, err := within(manifest.Root, f.Path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if _, err := os.Lstat(src); os.IsNotExist(err) {
+			// already restored by an earlier, interrupted restore
+			continue
+		}
+
+		if _, err := os.Lstat(dst); err == nil {
+			return nil, errors.Errorf("refusing to overwrite %s", dst)
+		}
+
+		moves = append(moves, pending{src, dst})
+	}
+
+	for _, m := range moves {
+		if err := move(m.src, m.dst); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return manifest, errors.WithStack(os.RemoveAll(dir))
+}
+
+// Expired returns the manifests of the quarantine directories beneath root that were created before the cutoff
+func Expired(root string, cutoff time.Time) ([]string, error) {
+	manifests, err := filepath.Glob(filepath.Join(root, QuarantineDirectory, "*", ManifestFile))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	expired := make([]string, 0)
+
+	for _, path := range manifests {
+		manifest, err := readManifest(path)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if manifest.Created.Before(cutoff) {
+			expired = append(expired, path)
+		}
+	}
+
+	sort.Strings(expired)
+
+	return expired, nil
+}
+
+// Purge permanently deletes the quarantine directory of a manifest, which must be beneath root
+func Purge(root, manifestPath string) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(manifestPath))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	rel, err := filepath.Rel(filepath.Join(root, QuarantineDirectory), dir)
+	if err != nil || rel == "." || strings.Contains(rel, string(filepath.Separator)) || strings.HasPrefix(rel, "..") {
+		return errors.Errorf("not a quarantine directory in %s: %s", root, dir)
+	}
+
+	return errors.WithStack(os.RemoveAll(dir))
+}
+
+// within joins the relative path to root, refusing paths that escape it, including through symlinks
+func within(root, rel string) (string, error) {
+	if filepath.IsAbs(rel) {
+		return "", errors.Errorf("refusing absolute path: %s", rel)
+	}
+
+	path := filepath.Join(root, rel)
+
+	if r, err := filepath.Rel(root, path); err != nil || r == "." || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("refusing path outside %s: %s", root, rel)
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	// the deepest existing parent must resolve to within the root
+	parent := filepath.Dir(path)
+	for {
+		realParent, err := filepath.EvalSymlinks(parent)
+		if os.IsNotExist(err) {
+			parent = filepath.Dir(parent)
+			continue
+		} else if err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		if r, err := filepath.Rel(realRoot, realParent); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return "", errors.Errorf("refusing path outside %s: %s", root, rel)
+		}
+
+		return path, nil
+	}
+}
+
+func move(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.Rename(src, dst))
+}
+
+func writeManifest(path string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.WriteFile(path, data, 0600))
+}
+
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse manifest: %s", path)
+	}
+
+	return manifest, nil
+}
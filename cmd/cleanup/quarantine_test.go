@@ -0,0 +1,131 @@
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, root, rel, content string) {
+	path := filepath.Join(root, rel)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestQuarantineAndRestore(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+
+	writeFile(t, root, "Local Files/Note Images/A/one.jpg", "one")
+	writeFile(t, root, "Local Files/Note Files/B/two.pdf", "two!")
+	writeFile(t, root, "Local Files/Note Images/C/keep.jpg", "keep")
+
+	manifestPath, manifest, err := Quarantine(root, []string{
+		"Local Files/Note Images/A/one.jpg",
+		"Local Files/Note Files/B/two.pdf",
+		"Local Files/Note Images/D/gone.jpg",
+	}, now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, QuarantineDirectory, "2024-05-01T093000", ManifestFile), manifestPath)
+	assert.Equal(t, []*Quarantined{
+		{Path: "Local Files/Note Images/A/one.jpg", Size: 3},
+		{Path: "Local Files/Note Files/B/two.pdf", Size: 4},
+	}, manifest.Files)
+
+	assert.NoFileExists(t, filepath.Join(root, "Local Files/Note Images/A/one.jpg"))
+	assert.FileExists(t, filepath.Join(root, QuarantineDirectory, "2024-05-01T093000", "Local Files/Note Images/A/one.jpg"))
+	assert.FileExists(t, filepath.Join(root, "Local Files/Note Images/C/keep.jpg"))
+
+	restored, err := Restore(manifestPath)
+
+	assert.NoError(t, err)
+	assert.Len(t, restored.Files, 2)
+	assert.FileExists(t, filepath.Join(root, "Local Files/Note Images/A/one.jpg"))
+	assert.FileExists(t, filepath.Join(root, "Local Files/Note Files/B/two.pdf"))
+	assert.NoDirExists(t, filepath.Join(root, QuarantineDirectory, "2024-05-01T093000"))
+}
+
+func TestQuarantineNothing(t *testing.T) {
+	root := t.TempDir()
+
+	manifestPath, manifest, err := Quarantine(root, []string{"Local Files/Note Images/A/missing.jpg"}, time.Now())
+
+	assert.NoError(t, err)
+	assert.Empty(t, manifestPath)
+	assert.Empty(t, manifest.Files)
+	assert.NoDirExists(t, filepath.Join(root, QuarantineDirectory))
+}
+
+func TestQuarantineRefusesPathsOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	writeFile(t, parent, "outside.txt", "secret")
+	writeFile(t, root, "Local Files/Note Images/A/one.jpg", "one")
+
+	for _, file := range []string{"../outside.txt", "Local Files/../../outside.txt", filepath.Join(parent, "outside.txt")} {
+		_, _, err := Quarantine(root, []string{"Local Files/Note Images/A/one.jpg", file}, time.Now())
+		assert.Error(t, err, file)
+	}
+
+	// nothing is moved if any path is refused
+	assert.FileExists(t, filepath.Join(root, "Local Files/Note Images/A/one.jpg"))
+	assert.FileExists(t, filepath.Join(parent, "outside.txt"))
+}
+
+func TestQuarantineRefusesSymlinksOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	writeFile(t, parent, "elsewhere/one.jpg", "one")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "Local Files"), 0755))
+	assert.NoError(t, os.Symlink(filepath.Join(parent, "elsewhere"), filepath.Join(root, "Local Files", "Note Images")))
+
+	_, _, err := Quarantine(root, []string{"Local Files/Note Images/one.jpg"}, time.Now())
+
+	assert.Error(t, err)
+	assert.FileExists(t, filepath.Join(parent, "elsewhere/one.jpg"))
+}
+
+func TestRestoreRefusesToOverwrite(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "Local Files/Note Images/A/one.jpg", "one")
+
+	manifestPath, _, err := Quarantine(root, []string{"Local Files/Note Images/A/one.jpg"}, time.Now())
+	assert.NoError(t, err)
+
+	writeFile(t, root, "Local Files/Note Images/A/one.jpg", "new")
+
+	_, err = Restore(manifestPath)
+
+	assert.Error(t, err)
+	assert.FileExists(t, manifestPath)
+}
+
+func TestExpiredAndPurge(t *testing.T) {
+	root := t.TempDir()
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	writeFile(t, root, "Local Files/Note Images/A/old.jpg", "old")
+	old, _, err := Quarantine(root, []string{"Local Files/Note Images/A/old.jpg"}, now.AddDate(0, 0, -40))
+	assert.NoError(t, err)
+
+	writeFile(t, root, "Local Files/Note Images/B/new.jpg", "new")
+	recent, _, err := Quarantine(root, []string{"Local Files/Note Images/B/new.jpg"}, now.AddDate(0, 0, -2))
+	assert.NoError(t, err)
+
+	expired, err := Expired(root, now.AddDate(0, 0, -30))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{old}, expired)
+
+	assert.NoError(t, Purge(root, old))
+	assert.NoDirExists(t, filepath.Dir(old))
+	assert.FileExists(t, recent)
+
+	assert.Error(t, Purge(root, filepath.Join(root, "Local Files", ManifestFile)))
+	assert.Error(t, Purge(root, filepath.Join(root, QuarantineDirectory, ManifestFile)))
+	assert.DirExists(t, filepath.Join(root, "Local Files"))
+}
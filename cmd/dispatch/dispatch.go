@@ -0,0 +1,184 @@
+package dispatch
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/db/exporter"
+	"github.com/mnadel/freddiebear/ext"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optExec      bool
+	optExportDir string
+)
+
+// Kind is the kind of shell action that a dispatched argument results in
+type Kind string
+
+const (
+	// OpenURL opens a bear:// URL
+	OpenURL Kind = "url"
+	// Copy copies text to the clipboard
+	Copy Kind = "copy"
+	// RevealFile reveals a file in Finder
+	RevealFile Kind = "reveal"
+)
+
+// Result is the shell action that an argument resolves to
+type Result struct {
+	Kind  Kind
+	Value string
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dispatch <arg>",
+		Short: "Route an x-fb argument to its action",
+		Long: `Turn an argument (an x-fb-<action>:<payload>, or a note ID) generated by another command into the bear:// URL to open,
+the text to copy, or the exported file to reveal. Actions are create, open, append, copy-link and reveal.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runner,
+	}
+
+	cmd.Flags().BoolVar(&optExec, "exec", false, "perform the action (open the URL, copy the text, or reveal the file) rather than print it")
+	cmd.Flags().StringVar(&optExportDir, "export-dir", "", "directory notes are exported to, for reveal (default: export.directory from config)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	exportDir := optExportDir
+	if exportDir == "" {
+		exportDir = config.ExpandHome(cfg.Export.Directory)
+	}
+
+	result, err := Dispatch(args[0], func(id string) (string, error) {
+		return exportedPath(exportDir, id)
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if optExec {
+		return errors.WithStack(execute(result))
+	}
+
+	fmt.Println(result.Value)
+
+	return nil
+}
+
+// Dispatch resolves the argument to its shell action. An argument that isn't an x-fb argument is
+// treated as the ID of a note to open. findExport returns the exported file of the note with an ID.
+func Dispatch(s string, findExport func(id string) (string, error)) (*Result, error) {
+	if !ext.IsArg(s) {
+		return &Result{OpenURL, openNote(ext.Payload{ID: s})}, nil
+	}
+
+	arg, err := ext.ParseArg(s)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	p := arg.Payload
+
+	switch arg.Action {
+	case ext.Create:
+		return &Result{OpenURL, bearurl.Create{
+			Title:  p.Title,
+			Text:   p.Text,
+			Tags:   p.Tags,
+			Window: bearurl.Window{OpenNote: true, ShowWindow: true, Edit: true},
+		}.String()}, nil
+	case ext.Open:
+		return &Result{OpenURL, openNote(p)}, nil
+	case ext.Append:
+		return &Result{OpenURL, bearurl.AddText{
+			ID:      p.ID,
+			Title:   p.Title,
+			Text:    p.Text,
+			Header:  p.Header,
+			Mode:    bearurl.Append,
+			NewLine: true,
+			Tags:    p.Tags,
+			Window:  bearurl.Window{Background: true},
+		}.String()}, nil
+	case ext.CopyLink:
+		// prefer the ID, which doesn't change when the note is renamed
+		target := bearurl.OpenNote{ID: p.ID, Header: p.Header}
+		if p.ID == "" {
+			target.Title = p.Title
+		}
+		link := target.String()
+		if p.Title != "" {
+			link = fmt.Sprintf("[%s](%s)", p.Title, link)
+		}
+		return &Result{Copy, link}, nil
+	case ext.Reveal:
+		if p.ID == "" {
+			return nil, errors.Errorf("reveal requires a note ID: %s", s)
+		}
+		path, err := findExport(p.ID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &Result{RevealFile, path}, nil
+	}
+
+	return nil, errors.Errorf("unsupported action: %s", arg.Action)
+}
+
+func openNote(p ext.Payload) string {
+	return bearurl.OpenNote{
+		ID:     p.ID,
+		Title:  p.Title,
+		Header: p.Header,
+		Window: bearurl.Window{OpenNote: true, ShowWindow: true, Edit: true},
+	}.String()
+}
+
+// exportedPath returns the path of the note's exported file in the export directory
+func exportedPath(directory, id string) (string, error) {
+	if directory == "" {
+		return "", errors.New("no export directory: use --export-dir or set export.directory")
+	}
+
+	exp, err := exporter.NewExporter(directory)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	path, ok := exp.Path(db.NoteSHA(id))
+	if !ok {
+		return "", errors.Errorf("note %s hasn't been exported to %s", id, directory)
+	}
+
+	return path, nil
+}
+
+func execute(result *Result) error {
+	switch result.Kind {
+	case OpenURL:
+		return exec.Command("open", result.Value).Run()
+	case Copy:
+		pbcopy := exec.Command("pbcopy")
+		pbcopy.Stdin = strings.NewReader(result.Value)
+		return pbcopy.Run()
+	case RevealFile:
+		return exec.Command("open", "-R", result.Value).Run()
+	}
+
+	return errors.Errorf("unknown action: %s", result.Kind)
+}
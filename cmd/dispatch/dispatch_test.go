@@ -0,0 +1,100 @@
+package dispatch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/ext"
+	"github.com/stretchr/testify/assert"
+)
+
+func noExports(id string) (string, error) {
+	return "", fmt.Errorf("no exports")
+}
+
+func dispatchURL(t *testing.T, arg string) *bearurl.URL {
+	result, err := Dispatch(arg, noExports)
+	assert.NoError(t, err)
+	assert.Equal(t, OpenURL, result.Kind)
+
+	u, err := bearurl.Parse(result.Value)
+	assert.NoError(t, err)
+
+	return u
+}
+
+func TestDispatchCreate(t *testing.T) {
+	u := dispatchURL(t, ext.NewArg(ext.Create, ext.Payload{Title: "A & B", Tags: []string{"work"}}).String())
+	assert.Equal(t, "create", u.Action)
+	assert.Equal(t, "A & B", u.Get("title"))
+	assert.Equal(t, "work", u.Get("tags"))
+	assert.Equal(t, "yes", u.Get("edit"))
+
+	// legacy args, as generated by search
+	u = dispatchURL(t, "x-fb-create:My Note")
+	assert.Equal(t, "create", u.Action)
+	assert.Equal(t, "My Note", u.Get("title"))
+}
+
+func TestDispatchOpen(t *testing.T) {
+	u := dispatchURL(t, ext.NewArg(ext.Open, ext.Payload{ID: "ABC-123", Header: "Log"}).String())
+	assert.Equal(t, "open-note", u.Action)
+	assert.Equal(t, "ABC-123", u.Get("id"))
+	assert.Equal(t, "Log", u.Get("header"))
+
+	// a bare note ID opens the note
+	u = dispatchURL(t, "ABC-123")
+	assert.Equal(t, "open-note", u.Action)
+	assert.Equal(t, "ABC-123", u.Get("id"))
+}
+
+func TestDispatchAppend(t *testing.T) {
+	u := dispatchURL(t, ext.NewArg(ext.Append, ext.Payload{Title: "Inbox", Text: "milk"}).String())
+	assert.Equal(t, "add-text", u.Action)
+	assert.Equal(t, "Inbox", u.Get("title"))
+	assert.Equal(t, "milk", u.Get("text"))
+	assert.Equal(t, "append", u.Get("mode"))
+	assert.Equal(t, "no", u.Get("open_note"))
+}
+
+func TestDispatchCopyLink(t *testing.T) {
+	result, err := Dispatch(ext.NewArg(ext.CopyLink, ext.Payload{ID: "ABC-123", Title: "My Note"}).String(), noExports)
+	assert.NoError(t, err)
+	assert.Equal(t, &Result{Copy, "[My Note](bear://x-callback-url/open-note?id=ABC-123)"}, result)
+
+	result, err = Dispatch(ext.NewArg(ext.CopyLink, ext.Payload{ID: "ABC-123"}).String(), noExports)
+	assert.NoError(t, err)
+	assert.Equal(t, "bear://x-callback-url/open-note?id=ABC-123", result.Value)
+}
+
+func TestDispatchReveal(t *testing.T) {
+	dir := t.TempDir()
+	filename := fmt.Sprintf("My Note (%s).md", db.NoteSHA("ABC-123"))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, filename), []byte("text"), 0644))
+
+	find := func(id string) (string, error) {
+		return exportedPath(dir, id)
+	}
+
+	result, err := Dispatch(ext.NewArg(ext.Reveal, ext.Payload{ID: "ABC-123"}).String(), find)
+	assert.NoError(t, err)
+	assert.Equal(t, &Result{RevealFile, filepath.Join(dir, filename)}, result)
+
+	_, err = Dispatch(ext.NewArg(ext.Reveal, ext.Payload{ID: "XYZ"}).String(), find)
+	assert.Error(t, err)
+
+	_, err = Dispatch(ext.NewArg(ext.Reveal, ext.Payload{Title: "My Note"}).String(), find)
+	assert.Error(t, err)
+
+	_, err = exportedPath("", "ABC-123")
+	assert.Error(t, err)
+}
+
+func TestDispatchErrors(t *testing.T) {
+	_, err := Dispatch("x-fb-delete:ABC-123", noExports)
+	assert.Error(t, err)
+}
@@ -0,0 +1,382 @@
+package dupes
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// DefaultThreshold is the similarity above which notes are near-duplicates
+	DefaultThreshold = 0.8
+	// DefaultMinWords is the number of words a note needs for its content to be compared
+	DefaultMinWords = 5
+)
+
+var (
+	optThreshold float64
+	optMinWords  int
+	optFormat    string
+)
+
+// Cluster is a group of notes with the same, or nearly the same, content
+type Cluster struct {
+	// Exact is true if the notes' contents are identical (ignoring case and whitespace)
+	Exact bool
+	// Similarity is the lowest estimated similarity between notes that were matched
+	Similarity float64
+	Notes      []*db.Record
+}
+
+// TitleGroup is a group of notes sharing a title, which makes [[wikilinks]] to it ambiguous
+type TitleGroup struct {
+	Title string
+	Notes []*db.Record
+}
+
+// AttachmentGroup is a group of attachments with the same filename and size
+type AttachmentGroup struct {
+	Filename    string
+	Size        int64
+	Attachments []*db.AttachmentInfo
+}
+
+// Report is everything found to be duplicated
+type Report struct {
+	Clusters    []*Cluster
+	Titles      []*TitleGroup
+	Attachments []*AttachmentGroup
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dupes",
+		Short: "Find duplicate notes",
+		Long:  "Find notes with identical or nearly identical content, notes sharing a title, and attachments with the same filename and size",
+		Args:  cobra.NoArgs,
+		RunE:  runner,
+	}
+
+	cmd.Flags().Float64Var(&optThreshold, "threshold", DefaultThreshold, "similarity (0-1) above which notes are near-duplicates")
+	cmd.Flags().IntVar(&optMinWords, "min-words", DefaultMinWords, "ignore the content of notes with fewer words")
+	cmd.Flags().StringVar(&optFormat, "format", "text", "output format (text, alfred)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	if optThreshold <= 0 || optThreshold > 1 {
+		return fmt.Errorf("threshold must be between 0 and 1: %v", optThreshold)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	records, err := bearDB.Records()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	attachments, err := bearDB.AttachmentInventory()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	included := make([]*db.Record, 0, len(records))
+	for _, record := range records {
+		if !cfg.ExcludesNote(record.Title, strings.Split(record.Tags, ",")) {
+			included = append(included, record)
+		}
+	}
+
+	report := &Report{
+		Clusters:    findClusters(included, optThreshold, optMinWords),
+		Titles:      duplicateTitles(included),
+		Attachments: duplicateAttachments(cfg, attachments),
+	}
+
+	switch optFormat {
+	case "text":
+		writeText(os.Stdout, report)
+	case "alfred":
+		output, err := alfred.AlfredJSON(alfredItems(report))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(output)
+	default:
+		return fmt.Errorf("unknown format: %s", optFormat)
+	}
+
+	return nil
+}
+
+// findClusters groups notes whose contents' estimated similarity is at least the threshold
+func findClusters(records []*db.Record, threshold float64, minWords int) []*Cluster {
+	notes := make([]*db.Record, 0, len(records))
+	sigs := make([]*Signature, 0, len(records))
+	hashes := make([][32]byte, 0, len(records))
+
+	for _, record := range records {
+		words := Words(record.Text)
+		if len(words) == 0 || len(words) < minWords {
+			continue
+		}
+
+		notes = append(notes, record)
+		sigs = append(sigs, MinHash(Shingles(words)))
+		hashes = append(hashes, sha256.Sum256([]byte(strings.Join(words, " "))))
+	}
+
+	parent := make([]int, len(notes))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	lowest := make(map[int]float64)
+
+	for _, pair := range candidates(sigs) {
+		a, b := pair[0], pair[1]
+
+		similarity := sigs[a].Similarity(sigs[b])
+		if hashes[a] == hashes[b] {
+			similarity = 1
+		}
+
+		if similarity < threshold {
+			continue
+		}
+
+		ra, rb := find(a), find(b)
+		low := similarity
+		for _, r := range []int{ra, rb} {
+			if l, ok := lowest[r]; ok && l < low {
+				low = l
+			}
+		}
+
+		parent[ra] = rb
+		delete(lowest, ra)
+		lowest[rb] = low
+	}
+
+	members := make(map[int][]int)
+	for i := range notes {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+
+	clusters := make([]*Cluster, 0)
+
+	for root, indexes := range members {
+		if len(indexes) < 2 {
+			continue
+		}
+
+		cluster := &Cluster{Exact: true, Similarity: lowest[root]}
+		for _, i := range indexes {
+			cluster.Notes = append(cluster.Notes, notes[i])
+			if hashes[i] != hashes[indexes[0]] {
+				cluster.Exact = false
+			}
+		}
+
+		sortRecords(cluster.Notes)
+		clusters = append(clusters, cluster)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].Exact != clusters[j].Exact {
+			return clusters[i].Exact
+		}
+		if clusters[i].Similarity != clusters[j].Similarity {
+			return clusters[i].Similarity > clusters[j].Similarity
+		}
+		return clusters[i].Notes[0].Title < clusters[j].Notes[0].Title
+	})
+
+	return clusters
+}
+
+// duplicateTitles groups notes whose titles differ only by case and surrounding whitespace
+func duplicateTitles(records []*db.Record) []*TitleGroup {
+	byTitle := make(map[string]*TitleGroup)
+
+	for _, record := range records {
+		key := strings.ToLower(strings.TrimSpace(record.Title))
+		if key == "" {
+			continue
+		}
+
+		group, ok := byTitle[key]
+		if !ok {
+			group = &TitleGroup{Title: strings.TrimSpace(record.Title)}
+			byTitle[key] = group
+		}
+		group.Notes = append(group.Notes, record)
+	}
+
+	groups := make([]*TitleGroup, 0)
+	for _, group := range byTitle {
+		if len(group.Notes) > 1 {
+			sortRecords(group.Notes)
+			groups = append(groups, group)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].Title) < strings.ToLower(groups[j].Title)
+	})
+
+	return groups
+}
+
+// duplicateAttachments groups the attachments of notes that aren't trashed or excluded by their filename and size
+func duplicateAttachments(cfg *config.Config, attachments []*db.AttachmentInfo) []*AttachmentGroup {
+	type key struct {
+		filename string
+		size     int64
+	}
+
+	byKey := make(map[key]*AttachmentGroup)
+
+	for _, a := range attachments {
+		if a.NoteID == "" || a.Trashed || a.Filename == "" || cfg.ExcludesNote(a.NoteTitle, a.Tags) {
+			continue
+		}
+
+		k := key{strings.ToLower(a.Filename), a.Size}
+		group, ok := byKey[k]
+		if !ok {
+			group = &AttachmentGroup{Filename: a.Filename, Size: a.Size}
+			byKey[k] = group
+		}
+		group.Attachments = append(group.Attachments, a)
+	}
+
+	groups := make([]*AttachmentGroup, 0)
+	for _, group := range byKey {
+		if len(group.Attachments) > 1 {
+			sort.SliceStable(group.Attachments, func(i, j int) bool {
+				return group.Attachments[i].NoteTitle < group.Attachments[j].NoteTitle
+			})
+			groups = append(groups, group)
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Size != groups[j].Size {
+			return groups[i].Size > groups[j].Size
+		}
+		return groups[i].Filename < groups[j].Filename
+	})
+
+	return groups
+}
+
+func (c *Cluster) describe() string {
+	if c.Exact {
+		return "Exact duplicates"
+	}
+	return fmt.Sprintf("%.0f%% similar", c.Similarity*100)
+}
+
+func writeText(w io.Writer, report *Report) {
+	fmt.Fprintf(w, "## Duplicate content (%d)\n", len(report.Clusters))
+	for i, cluster := range report.Clusters {
+		fmt.Fprintf(w, "\n%d. %s\n", i+1, cluster.describe())
+		for _, note := range cluster.Notes {
+			fmt.Fprintf(w, "   - %s (%s)\n", note.Title, note.ID)
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Duplicate titles (%d)\n", len(report.Titles))
+	for i, group := range report.Titles {
+		fmt.Fprintf(w, "\n%d. %s\n", i+1, group.Title)
+		for _, note := range group.Notes {
+			fmt.Fprintf(w, "   - %s (%s)\n", note.Title, note.ID)
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Duplicate attachments (%d)\n", len(report.Attachments))
+	for i, group := range report.Attachments {
+		fmt.Fprintf(w, "\n%d. %s (%d bytes)\n", i+1, group.Filename, group.Size)
+		for _, a := range group.Attachments {
+			fmt.Fprintf(w, "   - %s (%s)\n", a.NoteTitle, a.NoteID)
+		}
+	}
+}
+
+// alfredItems lists each candidate note, to be opened, with what it's a duplicate of
+func alfredItems(report *Report) []*alfred.Item {
+	items := make([]*alfred.Item, 0)
+
+	for _, cluster := range report.Clusters {
+		for _, note := range cluster.Notes {
+			items = append(items, &alfred.Item{
+				Title:    note.Title,
+				Subtitle: fmt.Sprintf("%s · %d notes", cluster.describe(), len(cluster.Notes)),
+				Arg:      note.ID,
+				Valid:    true,
+			})
+		}
+	}
+
+	for _, group := range report.Titles {
+		for _, note := range group.Notes {
+			items = append(items, &alfred.Item{
+				Title:    note.Title,
+				Subtitle: fmt.Sprintf("Duplicate title · %d notes", len(group.Notes)),
+				Arg:      note.ID,
+				Valid:    true,
+			})
+		}
+	}
+
+	for _, group := range report.Attachments {
+		for _, a := range group.Attachments {
+			items = append(items, &alfred.Item{
+				Title:    a.NoteTitle,
+				Subtitle: fmt.Sprintf("Duplicate attachment: %s (%d bytes) · %d notes", group.Filename, group.Size, len(group.Attachments)),
+				Arg:      a.NoteID,
+				Valid:    true,
+			})
+		}
+	}
+
+	return items
+}
+
+func sortRecords(records []*db.Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Title != records[j].Title {
+			return records[i].Title < records[j].Title
+		}
+		return records[i].ID < records[j].ID
+	})
+}
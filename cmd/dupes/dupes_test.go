@@ -0,0 +1,130 @@
+package dupes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+const meeting = `# Planning
+We agreed to ship the new onboarding flow in March, after the design review.
+Alice owns the copy, Bob owns the metrics dashboard, and Carol will run the beta.
+Open questions: pricing for teams, whether to keep the legacy import, and the launch blog post.`
+
+func ids(records []*db.Record) []string {
+	result := make([]string, 0, len(records))
+	for _, r := range records {
+		result = append(result, r.ID)
+	}
+	return result
+}
+
+func TestSimilarity(t *testing.T) {
+	a := MinHash(Shingles(Words(meeting)))
+	b := MinHash(Shingles(Words(strings.ToUpper(meeting))))
+	c := MinHash(Shingles(Words(strings.Replace(meeting, "in March", "in April", 1))))
+	d := MinHash(Shingles(Words("Groceries: milk, eggs, bread, coffee, and something for dinner on Friday")))
+
+	assert.Equal(t, 1.0, a.Similarity(b))
+	assert.Greater(t, a.Similarity(c), 0.8)
+	assert.Less(t, a.Similarity(c), 1.0)
+	assert.Less(t, a.Similarity(d), 0.1)
+}
+
+func TestShingles(t *testing.T) {
+	assert.Equal(t, map[string]bool{"a b c": true, "b c d": true}, Shingles([]string{"a", "b", "c", "d"}))
+	assert.Equal(t, map[string]bool{"a b": true}, Shingles([]string{"a", "b"}))
+	assert.Empty(t, Shingles(nil))
+}
+
+func TestFindClusters(t *testing.T) {
+	records := []*db.Record{
+		{ID: "1", Title: "Planning", Text: meeting},
+		{ID: "2", Title: "Planning", Text: "  " + strings.ReplaceAll(meeting, "\n", "\n\n")},
+		{ID: "3", Title: "Planning (edited)", Text: strings.Replace(meeting, "in March", "in April", 1)},
+		{ID: "4", Title: "Groceries", Text: "Groceries: milk, eggs, bread, coffee, and something for dinner on Friday"},
+		{ID: "5", Title: "Groceries copy", Text: "Groceries: milk, eggs, bread, coffee, and something for dinner on Friday"},
+		{ID: "6", Title: "Short", Text: "# Inbox"},
+		{ID: "7", Title: "Short", Text: "# Inbox"},
+	}
+
+	clusters := findClusters(records, 0.8, 5)
+
+	assert.Len(t, clusters, 2)
+
+	assert.True(t, clusters[0].Exact)
+	assert.Equal(t, 1.0, clusters[0].Similarity)
+	assert.Equal(t, []string{"4", "5"}, ids(clusters[0].Notes))
+
+	assert.False(t, clusters[1].Exact)
+	assert.Greater(t, clusters[1].Similarity, 0.8)
+	assert.Less(t, clusters[1].Similarity, 1.0)
+	assert.Equal(t, []string{"1", "2", "3"}, ids(clusters[1].Notes))
+
+	assert.Len(t, findClusters(records, 1, 5), 2, "exact duplicates are always clustered")
+	assert.Len(t, findClusters(records, 0.8, 1), 3, "short notes are compared when min-words allows")
+}
+
+func TestDuplicateTitles(t *testing.T) {
+	groups := duplicateTitles([]*db.Record{
+		{ID: "1", Title: "Ideas"},
+		{ID: "2", Title: "ideas "},
+		{ID: "3", Title: "Other"},
+		{ID: "4", Title: ""},
+		{ID: "5", Title: ""},
+	})
+
+	assert.Len(t, groups, 1)
+	assert.Equal(t, "Ideas", groups[0].Title)
+	assert.Equal(t, []string{"1", "2"}, ids(groups[0].Notes))
+}
+
+func TestDuplicateAttachments(t *testing.T) {
+	cfg := &config.Config{Exclude: config.Exclude{Tags: []string{"private"}}}
+
+	attachment := func(note, filename string, size int64, tags ...string) *db.AttachmentInfo {
+		return &db.AttachmentInfo{
+			Attachment: db.Attachment{NoteTitle: "Note " + note, Filename: filename},
+			NoteID:     note,
+			Tags:       tags,
+			Size:       size,
+		}
+	}
+
+	trashed := attachment("4", "board.jpg", 1000)
+	trashed.Trashed = true
+
+	groups := duplicateAttachments(cfg, []*db.AttachmentInfo{
+		attachment("1", "board.jpg", 1000),
+		attachment("2", "Board.JPG", 1000),
+		attachment("3", "board.jpg", 2000),
+		trashed,
+		attachment("5", "board.jpg", 1000, "private"),
+		attachment("", "board.jpg", 1000),
+		attachment("6", "scan.pdf", 50),
+		attachment("7", "scan.pdf", 50),
+	})
+
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "board.jpg", groups[0].Filename)
+	assert.Equal(t, int64(1000), groups[0].Size)
+	assert.Len(t, groups[0].Attachments, 2)
+	assert.Equal(t, "scan.pdf", groups[1].Filename)
+}
+
+func TestAlfredItems(t *testing.T) {
+	report := &Report{
+		Clusters: []*Cluster{{Similarity: 0.875, Notes: []*db.Record{{ID: "1", Title: "A"}, {ID: "2", Title: "B"}}}},
+		Titles:   []*TitleGroup{{Title: "C", Notes: []*db.Record{{ID: "3", Title: "C"}, {ID: "4", Title: "C"}}}},
+	}
+
+	items := alfredItems(report)
+
+	assert.Len(t, items, 4)
+	assert.Equal(t, "88% similar · 2 notes", items[0].Subtitle)
+	assert.Equal(t, "2", items[1].Arg)
+	assert.Equal(t, "Duplicate title · 2 notes", items[2].Subtitle)
+}
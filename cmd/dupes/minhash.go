@@ -0,0 +1,126 @@
+package dupes
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+const (
+	// ShingleSize is the number of consecutive words in a shingle
+	ShingleSize = 3
+
+	numHashes = 128
+	numBands  = 32
+	bandRows  = numHashes / numBands
+)
+
+// Signature is a MinHash signature: for each of the hash functions, the smallest hash of any of a
+// text's shingles. The fraction of positions at which two signatures agree estimates the Jaccard
+// similarity of the texts' sets of shingles.
+type Signature [numHashes]uint64
+
+var seeds = func() [numHashes]uint64 {
+	var s [numHashes]uint64
+	x := uint64(0x2545f4914f6cdd1d)
+	for i := range s {
+		x = splitmix(x)
+		s[i] = x
+	}
+	return s
+}()
+
+// Words returns the lowercased words of the text
+func Words(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+// Shingles returns the set of runs of ShingleSize consecutive words. Texts shorter than that are a single shingle.
+func Shingles(words []string) map[string]bool {
+	shingles := make(map[string]bool)
+
+	if len(words) == 0 {
+		return shingles
+	}
+
+	if len(words) < ShingleSize {
+		shingles[strings.Join(words, " ")] = true
+		return shingles
+	}
+
+	for i := 0; i+ShingleSize <= len(words); i++ {
+		shingles[strings.Join(words[i:i+ShingleSize], " ")] = true
+	}
+
+	return shingles
+}
+
+// MinHash computes the signature of a set of shingles
+func MinHash(shingles map[string]bool) *Signature {
+	sig := &Signature{}
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+
+	for shingle := range shingles {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		base := h.Sum64()
+
+		for i, seed := range seeds {
+			if v := splitmix(base ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+
+	return sig
+}
+
+// Similarity estimates the Jaccard similarity of the texts that the signatures were computed from
+func (s *Signature) Similarity(other *Signature) float64 {
+	same := 0
+	for i := range s {
+		if s[i] == other[i] {
+			same++
+		}
+	}
+	return float64(same) / numHashes
+}
+
+// candidates returns the pairs of signatures (by index) that share at least one band, i.e. the
+// locality-sensitive hashing candidates for being similar, so that every pair needn't be compared
+func candidates(sigs []*Signature) [][2]int {
+	seen := make(map[[2]int]bool)
+	pairs := make([][2]int, 0)
+
+	for band := 0; band < numBands; band++ {
+		buckets := make(map[[bandRows]uint64][]int)
+
+		for i, sig := range sigs {
+			var key [bandRows]uint64
+			copy(key[:], sig[band*bandRows:(band+1)*bandRows])
+			buckets[key] = append(buckets[key], i)
+		}
+
+		for _, bucket := range buckets {
+			for a := 0; a < len(bucket); a++ {
+				for b := a + 1; b < len(bucket); b++ {
+					pair := [2]int{bucket[a], bucket[b]}
+					if !seen[pair] {
+						seen[pair] = true
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+	}
+
+	return pairs
+}
+
+func splitmix(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
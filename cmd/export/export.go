@@ -6,8 +6,10 @@ import (
 	"log"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
+	"github.com/mnadel/freddiebear/config"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/mnadel/freddiebear/db/exporter"
 	"github.com/pkg/errors"
@@ -19,8 +21,10 @@ const (
 )
 
 var (
-	preview bool
-	list    bool
+	preview             bool
+	list                bool
+	optScope            string
+	optExcludeEncrypted bool
 
 	imageFileExtensions = map[string]bool{
 		".bmp":  true,
@@ -47,21 +51,38 @@ func New() *cobra.Command {
 
 	searchCmd.Flags().BoolVar(&preview, "preview", false, "list files that would be exported")
 	searchCmd.Flags().BoolVar(&list, "list", false, "list files in export directory")
+	searchCmd.Flags().StringVar(&optScope, "scope", string(db.Active), "notes to include: "+db.ScopeNames())
+	searchCmd.Flags().BoolVar(&optExcludeEncrypted, "exclude-encrypted", false, "skip encrypted (and locked) notes")
 
 	return searchCmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
+	scope, err := db.ParseScope(optScope)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	defer bearDB.Close()
 
+	bearDB.SetScope(scope)
+	bearDB.SetExcludeEncrypted(optExcludeEncrypted)
+
+	dest := exportDirectory(args[0], scope)
+
 	if preview {
-		return bearDB.Export(printingExporter(args[0]))
+		return bearDB.Export(excludingExporter(cfg, printingExporter(dest)))
 	} else if list {
-		files, err := exporter.ListFiles(args[0])
+		files, err := exporter.ListFiles(dest)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -80,26 +101,33 @@ func runner(cmd *cobra.Command, args []string) error {
 		return errors.WithStack(fmt.Errorf("not a directory: %s", args[0]))
 	}
 
-	exp, err := writingExporter(args[0])
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	exp, err := writingExporter(dest)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	if err := bearDB.Export(exp); err != nil {
+	if err := bearDB.Export(excludingExporter(cfg, encryptedExporter(exp))); err != nil {
 		return errors.WithStack(err)
 	}
 
+	// excluded notes are still considered current, so any previously-exported copies aren't archived
+	bearDB.SetExcludeEncrypted(false)
+
 	records, err := bearDB.Records()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	exporter, err := exporter.NewExporter(args[0])
+	exporter, err := exporter.NewExporter(dest)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	trashDir := path.Join(args[0], RelativeTrashDirectoryPath)
+	trashDir := path.Join(dest, RelativeTrashDirectoryPath)
 	_, err = os.Stat(trashDir)
 	if os.IsNotExist(err) {
 		if err := os.Mkdir(trashDir, 0755); err != nil {
@@ -107,13 +135,24 @@ func runner(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if err := writeAttachmentMappings(args[0], bearDB); err != nil {
+	if err := writeAttachmentMappings(dest, bearDB); err != nil {
 		errors.WithStack(err)
 	}
 
 	return exporter.Archive(records, trashDir)
 }
 
+// exportDirectory is the directory that the scope's notes are exported to: the destination itself for
+// active notes, and a subdirectory named after the scope for the others, so that exporting one scope
+// never archives the files exported for another
+func exportDirectory(destination string, scope db.Scope) string {
+	if scope == db.Active {
+		return destination
+	}
+
+	return path.Join(destination, string(scope))
+}
+
 func writeAttachmentMappings(destinationDir string, bearDB *db.DB) error {
 	attachments, err := bearDB.AllAttachments()
 	if err != nil {
@@ -142,6 +181,52 @@ func writeAttachmentMappings(destinationDir string, bearDB *db.DB) error {
 	return nil
 }
 
+// excludingExporter skips notes that are excluded by the config
+func excludingExporter(cfg *config.Config, exp db.Exporter) db.Exporter {
+	return func(record *db.Record) error {
+		if cfg.ExcludesNote(record.Title, strings.Split(record.Tags, ",")) {
+			return nil
+		}
+
+		return exp(record)
+	}
+}
+
+// encryptedExporter exports a stub, with the note's metadata, in place of each encrypted note
+func encryptedExporter(exp db.Exporter) db.Exporter {
+	return func(record *db.Record) error {
+		if record.Encrypted {
+			stub := *record
+			stub.Text = encryptedStub(record)
+			record = &stub
+		}
+
+		return exp(record)
+	}
+}
+
+// encryptedStub is the front matter and heading exported for an encrypted note, whose text Bear only
+// stores encrypted
+func encryptedStub(record *db.Record) string {
+	var tags []string
+	for _, tag := range db.SplitTags(record.Tags) {
+		tags = append(tags, strconv.Quote(tag))
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString("---\n")
+	fmt.Fprintf(&builder, "title: %s\n", strconv.Quote(record.Title))
+	fmt.Fprintf(&builder, "id: %s\n", record.ID)
+	fmt.Fprintf(&builder, "modified: %s\n", record.ModificationDate)
+	fmt.Fprintf(&builder, "tags: [%s]\n", strings.Join(tags, ", "))
+	builder.WriteString("encrypted: true\n")
+	builder.WriteString("---\n\n")
+	fmt.Fprintf(&builder, "# %s\n\n", record.Title)
+	builder.WriteString("This note is encrypted in Bear, so its contents aren't exported.\n")
+
+	return builder.String()
+}
+
 func printingExporter(destinationDir string) db.Exporter {
 	return func(record *db.Record) error {
 		fmt.Println(path.Join(destinationDir, exporter.BuildFilename(record)))
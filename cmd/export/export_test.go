@@ -0,0 +1,44 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptedExporter(t *testing.T) {
+	exported := make([]*db.Record, 0)
+	exp := encryptedExporter(func(record *db.Record) error {
+		exported = append(exported, record)
+		return nil
+	})
+
+	plain := &db.Record{ID: "A", Title: "Plain", Text: "# Plain\nhello"}
+	encrypted := &db.Record{ID: "B", Title: `Say "hi"`, ModificationDate: "2024-03-15 11:30:00", Tags: "work,work/secret", Encrypted: true}
+
+	assert.NoError(t, exp(plain))
+	assert.NoError(t, exp(encrypted))
+
+	assert.Same(t, plain, exported[0])
+	assert.Equal(t, `---
+title: "Say \"hi\""
+id: B
+modified: 2024-03-15 11:30:00
+tags: ["work", "work/secret"]
+encrypted: true
+---
+
+# Say "hi"
+
+This note is encrypted in Bear, so its contents aren't exported.
+`, exported[1].Text)
+	assert.Equal(t, "B", exported[1].ID)
+	assert.Empty(t, encrypted.Text)
+}
+
+func TestExportDirectory(t *testing.T) {
+	assert.Equal(t, "/backup", exportDirectory("/backup", db.Active))
+	assert.Equal(t, "/backup/archived", exportDirectory("/backup", db.Archived))
+	assert.Equal(t, "/backup/all", exportDirectory("/backup", db.All))
+}
@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mnadel/freddiebear/config"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/mnadel/freddiebear/util"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+var (
+	optScope            string
+	optExcludeEncrypted bool
+)
+
 func New() *cobra.Command {
 	graphCmd := &cobra.Command{
 		Use:   "graph [term]",
@@ -19,16 +25,32 @@ func New() *cobra.Command {
 		RunE:  runner,
 	}
 
+	graphCmd.Flags().StringVar(&optScope, "scope", string(db.Active), "notes to include: "+db.ScopeNames())
+	graphCmd.Flags().BoolVar(&optExcludeEncrypted, "exclude-encrypted", false, "skip encrypted (and locked) notes")
+
 	return graphCmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
+	scope, err := db.ParseScope(optScope)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	defer bearDB.Close()
 
+	bearDB.SetScope(scope)
+	bearDB.SetExcludeEncrypted(optExcludeEncrypted)
+
 	graph, err := bearDB.QueryGraph()
 	if err != nil {
 		return errors.WithStack(err)
@@ -40,6 +62,10 @@ func runner(cmd *cobra.Command, args []string) error {
 	includedEdges := make(map[*db.Edge]bool)
 
 	for i, edge := range graph {
+		if excluded(cfg, edge.Source) || excluded(cfg, edge.Target) {
+			continue
+		}
+
 		if len(args) == 1 && args[0] != "" {
 			haystack := strings.Builder{}
 			haystack.WriteString(strings.ToLower(edge.Source.Title))
@@ -83,6 +109,10 @@ func runner(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func excluded(cfg *config.Config, n *db.Result) bool {
+	return cfg.ExcludesNote(n.Title, strings.Split(n.Tags, ","))
+}
+
 func nodeLabel(n *db.Result) string {
 	tags := n.UniqueTags()
 	if len(tags) > 0 {
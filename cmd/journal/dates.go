@@ -0,0 +1,115 @@
+package journal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/daily"
+)
+
+var (
+	relativeDaysRegex = regexp.MustCompile(`^([+-]\d+)\s*([dw]?)$`)
+	agoRegex          = regexp.MustCompile(`^(\d+)\s+(day|days|week|weeks)\s+ago$`)
+	inRegex           = regexp.MustCompile(`^in\s+(\d+)\s+(day|days|week|weeks)$`)
+
+	weekdays = map[string]time.Weekday{
+		"sunday": time.Sunday, "sun": time.Sunday,
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+	}
+)
+
+// resolveDates turns a date expression (today, yesterday, +3, 2 days ago, 2024-03-01, last friday,
+// next monday, ...) into the day(s) it refers to, relative to now. A bare weekday is ambiguous, so
+// it resolves to both its previous and next occurrences.
+func resolveDates(expr string, now time.Time) ([]time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	expr = strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+
+	switch expr {
+	case "", "today", "now":
+		return []time.Time{today}, nil
+	case "yesterday":
+		return []time.Time{today.AddDate(0, 0, -1)}, nil
+	case "tomorrow":
+		return []time.Time{today.AddDate(0, 0, 1)}, nil
+	case "last week":
+		return []time.Time{today.AddDate(0, 0, -7)}, nil
+	case "next week":
+		return []time.Time{today.AddDate(0, 0, 7)}, nil
+	}
+
+	if day, err := time.ParseInLocation(dailyTitleFormat, expr, now.Location()); err == nil {
+		return []time.Time{day}, nil
+	}
+
+	if m := relativeDaysRegex.FindStringSubmatch(expr); m != nil {
+		return []time.Time{today.AddDate(0, 0, offsetDays(m[1], m[2]))}, nil
+	}
+
+	if m := agoRegex.FindStringSubmatch(expr); m != nil {
+		return []time.Time{today.AddDate(0, 0, -offsetDays(m[1], m[2][:1]))}, nil
+	}
+
+	if m := inRegex.FindStringSubmatch(expr); m != nil {
+		return []time.Time{today.AddDate(0, 0, offsetDays(m[1], m[2][:1]))}, nil
+	}
+
+	modifier, name := "", expr
+	if parts := strings.SplitN(expr, " ", 2); len(parts) == 2 {
+		modifier, name = parts[0], parts[1]
+	}
+
+	if weekday, ok := weekdays[name]; ok {
+		last, next := lastWeekday(today, weekday), nextWeekday(today, weekday)
+
+		switch modifier {
+		case "last":
+			return []time.Time{last}, nil
+		case "next":
+			return []time.Time{next}, nil
+		case "this":
+			return []time.Time{daily.BuiltinPeriod("week").Start(today).AddDate(0, 0, (int(weekday)+6)%7)}, nil
+		case "":
+			if today.Weekday() == weekday {
+				return []time.Time{today}, nil
+			}
+			return []time.Time{last, next}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot parse date: %s", expr)
+}
+
+// lastWeekday returns the most recent occurrence of the weekday, strictly before today
+func lastWeekday(today time.Time, weekday time.Weekday) time.Time {
+	delta := (int(today.Weekday()) - int(weekday) + 7) % 7
+	if delta == 0 {
+		delta = 7
+	}
+	return today.AddDate(0, 0, -delta)
+}
+
+// nextWeekday returns the next occurrence of the weekday, strictly after today
+func nextWeekday(today time.Time, weekday time.Weekday) time.Time {
+	delta := (int(weekday) - int(today.Weekday()) + 7) % 7
+	if delta == 0 {
+		delta = 7
+	}
+	return today.AddDate(0, 0, delta)
+}
+
+func offsetDays(count, unit string) int {
+	n, _ := strconv.Atoi(count)
+	if unit == "w" {
+		return n * 7
+	}
+	return n
+}
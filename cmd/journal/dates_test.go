@@ -0,0 +1,65 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveDates(t *testing.T) {
+	// a wednesday
+	now := time.Date(2024, 3, 6, 15, 30, 0, 0, time.Local)
+
+	tests := map[string][]string{
+		"":               {"2024-03-06"},
+		"today":          {"2024-03-06"},
+		"Yesterday":      {"2024-03-05"},
+		"tomorrow":       {"2024-03-07"},
+		"+3":             {"2024-03-09"},
+		"-1":             {"2024-03-05"},
+		"+2w":            {"2024-03-20"},
+		"2 days ago":     {"2024-03-04"},
+		"1 week ago":     {"2024-02-28"},
+		"in 3 days":      {"2024-03-09"},
+		"2024-03-01":     {"2024-03-01"},
+		"last friday":    {"2024-03-01"},
+		"next  Monday":   {"2024-03-11"},
+		"last wednesday": {"2024-02-28"},
+		"next wed":       {"2024-03-13"},
+		"this friday":    {"2024-03-08"},
+		"this monday":    {"2024-03-04"},
+		"friday":         {"2024-03-01", "2024-03-08"},
+		"wednesday":      {"2024-03-06"},
+		"last week":      {"2024-02-28"},
+	}
+
+	for expr, expected := range tests {
+		days, err := resolveDates(expr, now)
+		assert.NoError(t, err, expr)
+
+		actual := make([]string, len(days))
+		for i, day := range days {
+			actual[i] = day.Format(dailyTitleFormat)
+		}
+
+		assert.Equal(t, expected, actual, expr)
+	}
+}
+
+func TestResolveDatesInvalid(t *testing.T) {
+	now := time.Date(2024, 3, 6, 15, 30, 0, 0, time.Local)
+
+	for _, expr := range []string{"someday", "2024-13-01", "last fortnight", "+x"} {
+		_, err := resolveDates(expr, now)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestDateExpr(t *testing.T) {
+	assert.Equal(t, "", dateExpr(nil))
+	assert.Equal(t, "", dateExpr([]string{""}))
+	assert.Equal(t, "", dateExpr([]string{"  ", "\t"}))
+	assert.Equal(t, "last friday", dateExpr([]string{"last", "friday"}))
+	assert.Equal(t, "2 days ago", dateExpr([]string{" 2 days ago "}))
+}
@@ -2,8 +2,13 @@ package journal
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/daily"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -12,23 +17,61 @@ import (
 var (
 	optTagName       string
 	optTagAppendDate bool
+	optTemplate      bool
+	optPeriod        string
 )
 
 func New() *cobra.Command {
 	journalCmd := &cobra.Command{
-		Use:   "journal",
+		Use:   "journal [date]",
 		Short: "Daily journal helper",
-		Long:  "Display daily note ID, or <title>,<tag>",
-		RunE:  runner,
+		Long: `Display the ID of the note for the current day (or week, month, ...), or <title>,<tag> (or <title>,<tag>,<body> with --template).
+
+When a date is given (yesterday, tomorrow, +3, "2 days ago", 2024-03-01, friday, "last friday", "next monday", ...)
+generate the matching notes in Alfred Workflow's JSON schema format, where each item's arg is the same as above.`,
+		Args: cobra.ArbitraryArgs,
+		RunE: runner,
 	}
 
-	journalCmd.Flags().StringVar(&optTagName, "tag", "", "tag to add to journal entry")
-	journalCmd.Flags().BoolVar(&optTagAppendDate, "date", false, "append date (yyyy/mm) to tag")
+	journalCmd.PersistentFlags().StringVar(&optTagName, "tag", "", "tag to add to journal entry (default: journal.tag from config)")
+	journalCmd.PersistentFlags().BoolVar(&optTagAppendDate, "date", false, "append date (yyyy/mm) to tag (default: journal.date from config)")
+	journalCmd.PersistentFlags().BoolVar(&optTemplate, "template", false, "include the rendered, URL-encoded body for a new journal entry")
+	journalCmd.Flags().StringVar(&optPeriod, "period", "day", "journal period (day, week, month, quarter, year)")
+
+	journalCmd.AddCommand(newStatsCmd())
+	journalCmd.AddCommand(newRolloverCmd())
 
 	return journalCmd
 }
 
+// loadConfig loads the config, using it for any journal flags that weren't specified
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !cmd.Flags().Changed("tag") {
+		optTagName = cfg.Journal.Tag
+	}
+	if !cmd.Flags().Changed("date") {
+		optTagAppendDate = cfg.Journal.AppendDate
+	}
+
+	return cfg, nil
+}
+
 func runner(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	period, err := daily.LookupPeriod(optPeriod, cfg)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
@@ -36,36 +79,149 @@ func runner(cmd *cobra.Command, args []string) error {
 	defer bearDB.Close()
 
 	now := time.Now()
-	term := now.Format("2006-01-02")
 
-	results, err := bearDB.QueryTitles(term, true)
+	if expr := dateExpr(args); expr != "" {
+		return printDates(bearDB, cfg, period, expr, now)
+	}
+
+	term := period.Title(now)
+
+	id, err := findEntry(bearDB, term)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if id != "" {
+		fmt.Print(id)
+	} else {
+		arg, err := createArg(bearDB, cfg, period, now)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		fmt.Print(arg)
+	}
+
+	return nil
+}
+
+// dateExpr joins the args into a date expression, which is empty if they're empty or only whitespace
+// (e.g. an empty `{query}` from an Alfred script action), leaving the default output unchanged
+func dateExpr(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
+
+// printDates prints an Alfred item for each day the date expression refers to, either to open
+// the day's existing note or to create it
+func printDates(bearDB *db.DB, cfg *config.Config, period *daily.Period, expr string, now time.Time) error {
+	days, err := resolveDates(expr, now)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	var id string
+	items := make([]*alfred.Item, 0, len(days))
+
+	for _, day := range days {
+		term := period.Title(day)
+
+		id, err := findEntry(bearDB, term)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		item := &alfred.Item{
+			UID:   term,
+			Title: term,
+			Valid: true,
+		}
+
+		if id != "" {
+			item.Subtitle = fmt.Sprintf("Open %s", noteLabel(period, day))
+			item.Arg = id
+		} else {
+			item.Subtitle = fmt.Sprintf("Create %s", noteLabel(period, day))
+			item.Arg, err = createArg(bearDB, cfg, period, day)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	output, err := alfred.AlfredJSON(items)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Print(output)
+
+	return nil
+}
+
+func noteLabel(period *daily.Period, day time.Time) string {
+	if period.Name == "day" {
+		return day.Format("Monday's note")
+	}
+	return period.Name + "ly note"
+}
+
+// findEntry returns the ID of the note with the given title, or the empty string if there isn't one
+func findEntry(bearDB *db.DB, term string) (string, error) {
+	results, err := bearDB.QueryTitles(term, true)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
 
 	if len(results) > 1 {
-		return fmt.Errorf("found too many matches")
+		return "", fmt.Errorf("found too many matches")
 	} else if len(results) == 1 {
-		id = results[0].ID
+		return results[0].ID, nil
 	}
 
-	if id == "" {
-		fmt.Printf("%s,%s", now.Format("2006-01-02"), journalTag(now))
-	} else {
-		fmt.Print(id)
+	return "", nil
+}
+
+// createArg returns <title>,<tag> (or <title>,<tag>,<body> with --template) for a new note
+func createArg(bearDB *db.DB, cfg *config.Config, period *daily.Period, day time.Time) (string, error) {
+	term := period.Title(day)
+	tag := period.Tag(optTagName, optTagAppendDate, day)
+
+	if !optTemplate {
+		return fmt.Sprintf("%s,%s", term, tag), nil
 	}
 
-	return nil
+	body, err := renderEntry(bearDB, cfg, period, day, tag)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return fmt.Sprintf("%s,%s,%s", term, tag, bearurl.Encode(body)), nil
 }
 
-func journalTag(now time.Time) string {
-	if optTagName == "" && !optTagAppendDate {
-		return ""
-	} else if !optTagAppendDate {
-		return optTagName
+func renderEntry(bearDB *db.DB, cfg *config.Config, period *daily.Period, day time.Time, tag string) (string, error) {
+	data := newTemplateData(period, day, tag)
+
+	if period.Name == "day" {
+		records, err := bearDB.QueryRecordsByTitle(dailyTitlePattern)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		data.carryForward(previousDailyNote(records, day), cfg.Todos.BearSyntax)
 	} else {
-		return fmt.Sprintf("%s/%s/%s", optTagName, now.Format("2006"), now.Format("01"))
+		results, err := bearDB.QueryTitles(dailyTitlePattern, true)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+
+		titles := make([]string, len(results))
+		for i, r := range results {
+			titles[i] = r.Title
+		}
+
+		data.addDays(titles)
 	}
+
+	return renderTemplate(period.TemplateFile(cfg, day), data)
 }
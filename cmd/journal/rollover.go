@@ -0,0 +1,153 @@
+package journal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/todo"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optRolloverStale   int
+	optRolloverEncoded bool
+)
+
+// OpenItem is an incomplete todo from the previous daily note, along with how long it's been open
+type OpenItem struct {
+	*todo.Item
+	// Since is the title of the earliest daily note in an unbroken run of notes where the item was open
+	Since string
+	// Days is the number of days since the item was first open
+	Days int
+}
+
+func newRolloverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollover",
+		Short: "Carry forward incomplete todos",
+		Long:  "Print the incomplete todos from the most recent previous daily note, to seed today's note",
+		Args:  cobra.NoArgs,
+		RunE:  rolloverRunner,
+	}
+
+	cmd.Flags().IntVar(&optRolloverStale, "stale", 0, "also list the todos that have been open for more than this many days")
+	cmd.Flags().BoolVar(&optRolloverEncoded, "encoded", false, "URL-encode the output, for use in a bear:// x-callback-url")
+
+	return cmd
+}
+
+func rolloverRunner(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	records, err := bearDB.QueryRecordsByTitle(dailyTitlePattern)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	items := rolloverItems(records, time.Now(), cfg.Todos.BearSyntax)
+	output := rolloverText(items, optRolloverStale)
+
+	if optRolloverEncoded {
+		output = bearurl.Encode(output)
+	}
+
+	fmt.Print(output)
+
+	return nil
+}
+
+// rolloverItems returns the incomplete todos from the most recent daily note before day. Each item's
+// age is determined by walking back through the earlier daily notes for as long as the item stays open.
+func rolloverItems(records []*db.Record, day time.Time, bearSyntax bool) []*OpenItem {
+	previous := previousDailyNote(records, day)
+	if previous == nil {
+		return make([]*OpenItem, 0)
+	}
+
+	items := make([]*OpenItem, 0)
+	tracking := make(map[string]*OpenItem)
+
+	for _, item := range todo.Incomplete(todo.Parse([]byte(previous.Text), bearSyntax)) {
+		open := &OpenItem{Item: item, Since: previous.Title}
+		items = append(items, open)
+		tracking[normalizeTodo(item.Text)] = open
+	}
+
+	for entry := previous; len(tracking) > 0; {
+		// titles of daily notes always parse, previousDailyNote skips everything else
+		date, _ := time.Parse(dailyTitleFormat, entry.Title)
+		if entry = previousDailyNote(records, date); entry == nil {
+			break
+		}
+
+		stillOpen := make(map[string]*OpenItem)
+		for _, item := range todo.Incomplete(todo.Parse([]byte(entry.Text), bearSyntax)) {
+			key := normalizeTodo(item.Text)
+			if open, ok := tracking[key]; ok {
+				open.Since = entry.Title
+				stillOpen[key] = open
+			}
+		}
+
+		tracking = stillOpen
+	}
+
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, item := range items {
+		// daily titles are calendar dates, so count the days between them in UTC, which has no DST changes
+		since, _ := time.Parse(dailyTitleFormat, item.Since)
+		item.Days = int(midnight.Sub(since).Hours() / 24)
+	}
+
+	return items
+}
+
+// rolloverText renders the items as a Markdown task list, followed by the items that have been
+// open for more than stale days (if stale is positive)
+func rolloverText(items []*OpenItem, stale int) string {
+	b := strings.Builder{}
+
+	for _, item := range items {
+		b.WriteString(todo.Markdown([]*todo.Item{item.Item}))
+	}
+
+	if stale <= 0 {
+		return b.String()
+	}
+
+	staleItems := make([]*OpenItem, 0)
+	for _, item := range items {
+		if item.Days > stale {
+			staleItems = append(staleItems, item)
+		}
+	}
+
+	if len(staleItems) > 0 {
+		fmt.Fprintf(&b, "\n## Open for more than %s\n", util.Pluralize(stale, "day"))
+		for _, item := range staleItems {
+			fmt.Fprintf(&b, "- [ ] %s (since [[%s]], %s)\n", item.Text, item.Since, util.Pluralize(item.Days, "day"))
+		}
+	}
+
+	return b.String()
+}
+
+func normalizeTodo(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
@@ -0,0 +1,69 @@
+package journal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloverItems(t *testing.T) {
+	records := []*db.Record{
+		{Title: "2024-03-01", Text: "- [ ] call bob\n- [ ] file taxes\n- [ ] water plants\n"},
+		{Title: "2024-03-02", Text: "- [ ] call  Bob\n- [ ] water plants\n"},
+		{Title: "2024-03-03", Text: "- [ ] call bob\n- [x] water plants\n- [ ] file taxes\n"},
+		{Title: "2024-03-05", Text: "- [ ] call bob\n- [ ] file taxes\n- [x] done\n"},
+		{Title: "2024-03-06", Text: "- [ ] today's, not carried"},
+		{Title: "2024-03-04 retro", Text: "- [ ] not a daily note"},
+	}
+
+	day := time.Date(2024, 3, 6, 9, 0, 0, 0, time.Local)
+	items := rolloverItems(records, day, false)
+
+	assert.Equal(t, 2, len(items))
+
+	assert.Equal(t, "call bob", items[0].Text)
+	assert.Equal(t, "2024-03-01", items[0].Since)
+	assert.Equal(t, 5, items[0].Days)
+
+	// file taxes wasn't in the 03-02 note, so it's only been open since 03-03
+	assert.Equal(t, "file taxes", items[1].Text)
+	assert.Equal(t, "2024-03-03", items[1].Since)
+	assert.Equal(t, 3, items[1].Days)
+
+	assert.Equal(t, "- [ ] call bob\n- [ ] file taxes\n", rolloverText(items, 0))
+	assert.Equal(t, "- [ ] call bob\n- [ ] file taxes\n\n## Open for more than 4 days\n- [ ] call bob (since [[2024-03-01]], 5 days)\n", rolloverText(items, 4))
+	assert.Equal(t, "- [ ] call bob\n- [ ] file taxes\n", rolloverText(items, 10))
+}
+
+func TestRolloverItemsBearSyntax(t *testing.T) {
+	records := []*db.Record{
+		{Title: "2024-03-05", Text: "- call bob\n+ done\n"},
+	}
+
+	day := time.Date(2024, 3, 6, 9, 0, 0, 0, time.Local)
+
+	assert.Empty(t, rolloverItems(records, day, false))
+	assert.Equal(t, "call bob", rolloverItems(records, day, true)[0].Text)
+}
+
+func TestRolloverNoPreviousNote(t *testing.T) {
+	day := time.Date(2024, 3, 6, 9, 0, 0, 0, time.Local)
+
+	assert.Empty(t, rolloverItems(nil, day, false))
+	assert.Equal(t, "", rolloverText(nil, 3))
+}
+
+func TestRolloverItemsAcrossDST(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+
+	// clocks sprang forward on 2024-03-10, so the 9th to the 11th is only 47 hours
+	records := []*db.Record{{Title: "2024-03-09", Text: "- [ ] call bob\n"}}
+	day := time.Date(2024, 3, 11, 9, 0, 0, 0, newYork)
+
+	assert.Equal(t, 2, rolloverItems(records, day, false)[0].Days)
+}
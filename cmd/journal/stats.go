@@ -0,0 +1,426 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/daily"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optStatsSince  string
+	optStatsUntil  string
+	optStatsFormat string
+	optStatsLimit  int
+
+	heatmapLevels = []string{"·", "░", "▒", "▓", "█"}
+	heatmapColors = []string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"}
+)
+
+// Entry is a daily note
+type Entry struct {
+	Title string `json:"title"`
+	ID    string `json:"id"`
+	Words int    `json:"words"`
+
+	date time.Time
+}
+
+// MonthStats summarizes the entries within a month
+type MonthStats struct {
+	Month   string `json:"month"`
+	Entries int    `json:"entries"`
+	Words   int    `json:"words"`
+}
+
+// Stats summarizes the daily journal
+type Stats struct {
+	Since              string        `json:"since"`
+	Until              string        `json:"until"`
+	Entries            []*Entry      `json:"entries"`
+	Months             []*MonthStats `json:"months"`
+	Missing            []string      `json:"missing"`
+	CurrentStreak      int           `json:"currentStreak"`
+	LongestStreak      int           `json:"longestStreak"`
+	LongestStreakStart string        `json:"longestStreakStart,omitempty"`
+	LongestStreakEnd   string        `json:"longestStreakEnd,omitempty"`
+}
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Journal streaks, gaps and calendar",
+		Long:  "Report current and longest streaks, missing days, word counts, and a calendar heatmap of daily notes",
+		Args:  cobra.NoArgs,
+		RunE:  statsRunner,
+	}
+
+	cmd.Flags().StringVar(&optStatsSince, "since", "-30", "first day of the range (any date expression, e.g. 2024-01-01 or -90)")
+	cmd.Flags().StringVar(&optStatsUntil, "until", "today", "last day of the range (any date expression)")
+	cmd.Flags().StringVar(&optStatsFormat, "format", "text", "output format (text, json, html, alfred)")
+	cmd.Flags().IntVar(&optStatsLimit, "limit", 10, "maximum number of missing days to list in Alfred format")
+
+	return cmd
+}
+
+func statsRunner(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := time.Now()
+
+	since, err := resolveDate(optStatsSince, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	until, err := resolveDate(optStatsUntil, now)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if until.Before(since) {
+		return fmt.Errorf("--until (%s) is before --since (%s)", until.Format(dailyTitleFormat), since.Format(dailyTitleFormat))
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	records, err := bearDB.QueryRecordsByTitle(dailyTitlePattern)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	entries := dailyEntries(records)
+	stats := computeStats(entries, since, until, now)
+
+	switch optStatsFormat {
+	case "text":
+		printStats(stats)
+		fmt.Print(heatmap(stats.Entries, since, until))
+	case "json":
+		return errors.WithStack(json.NewEncoder(os.Stdout).Encode(stats))
+	case "html":
+		fmt.Print(heatmapHTML(stats, since, until))
+	case "alfred":
+		day, err := daily.LookupPeriod("day", cfg)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		items := make([]*alfred.Item, 0)
+
+		for i := len(stats.Missing) - 1; i >= 0 && len(items) < optStatsLimit; i-- {
+			date, _ := time.ParseInLocation(dailyTitleFormat, stats.Missing[i], now.Location())
+
+			arg, err := createArg(bearDB, cfg, day, date)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			items = append(items, &alfred.Item{
+				UID:      stats.Missing[i],
+				Title:    stats.Missing[i],
+				Subtitle: fmt.Sprintf("Create %s", noteLabel(day, date)),
+				Arg:      arg,
+				Valid:    true,
+			})
+		}
+
+		if len(items) == 0 {
+			items = append(items, &alfred.Item{Title: "No missing days", Valid: false})
+		}
+
+		output, err := alfred.AlfredJSON(items)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		fmt.Print(output)
+	default:
+		return fmt.Errorf("unknown format: %s", optStatsFormat)
+	}
+
+	return nil
+}
+
+// resolveDate resolves a date expression that must refer to exactly one day
+func resolveDate(expr string, now time.Time) (time.Time, error) {
+	days, err := resolveDates(expr, now)
+	if err != nil {
+		return time.Time{}, errors.WithStack(err)
+	} else if len(days) != 1 {
+		return time.Time{}, fmt.Errorf("ambiguous date: %s", expr)
+	}
+
+	return days[0], nil
+}
+
+// dailyEntries returns the records that are daily notes, in chronological order
+func dailyEntries(records []*db.Record) []*Entry {
+	entries := make([]*Entry, 0, len(records))
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		date, err := time.ParseInLocation(dailyTitleFormat, record.Title, time.Local)
+		if err != nil || seen[record.Title] {
+			continue
+		}
+		seen[record.Title] = true
+
+		entries = append(entries, &Entry{
+			Title: record.Title,
+			ID:    record.ID,
+			Words: len(strings.Fields(record.Text)),
+			date:  date,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Title < entries[j].Title
+	})
+
+	return entries
+}
+
+// computeStats computes streaks across all entries, and everything else within [since, until]
+func computeStats(entries []*Entry, since, until, now time.Time) *Stats {
+	first, last := since.Format(dailyTitleFormat), until.Format(dailyTitleFormat)
+
+	stats := &Stats{
+		Since:   first,
+		Until:   last,
+		Entries: make([]*Entry, 0),
+		Months:  make([]*MonthStats, 0),
+		Missing: make([]string, 0),
+	}
+
+	written := make(map[string]bool)
+	for _, entry := range entries {
+		written[entry.Title] = true
+
+		if entry.Title >= first && entry.Title <= last {
+			stats.Entries = append(stats.Entries, entry)
+
+			month := entry.Title[:7]
+			if len(stats.Months) == 0 || stats.Months[len(stats.Months)-1].Month != month {
+				stats.Months = append(stats.Months, &MonthStats{Month: month})
+			}
+			stats.Months[len(stats.Months)-1].Entries++
+			stats.Months[len(stats.Months)-1].Words += entry.Words
+		}
+	}
+
+	for day := since; !day.After(until); day = day.AddDate(0, 0, 1) {
+		if title := day.Format(dailyTitleFormat); !written[title] {
+			stats.Missing = append(stats.Missing, title)
+		}
+	}
+
+	// the current streak is still alive if today's entry hasn't been written yet
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !written[day.Format(dailyTitleFormat)] {
+		day = day.AddDate(0, 0, -1)
+	}
+	for written[day.Format(dailyTitleFormat)] {
+		stats.CurrentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for i, entry := range entries {
+		if i > 0 && entries[i-1].date.AddDate(0, 0, 1).Format(dailyTitleFormat) == entry.Title {
+			streak++
+		} else {
+			streak = 1
+		}
+
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+			stats.LongestStreakStart = entries[i-streak+1].Title
+			stats.LongestStreakEnd = entry.Title
+		}
+	}
+
+	return stats
+}
+
+func printStats(stats *Stats) {
+	fmt.Printf("Range:          %s – %s\n", stats.Since, stats.Until)
+	fmt.Printf("Entries:        %d\n", len(stats.Entries))
+	fmt.Printf("Current streak: %s\n", util.Pluralize(stats.CurrentStreak, "day"))
+
+	if stats.LongestStreak > 0 {
+		fmt.Printf("Longest streak: %s (%s – %s)\n", util.Pluralize(stats.LongestStreak, "day"), stats.LongestStreakStart, stats.LongestStreakEnd)
+	}
+
+	fmt.Printf("\nMissing days (%d):\n", len(stats.Missing))
+	for _, days := range missingRanges(stats.Missing) {
+		fmt.Printf("  %s\n", days)
+	}
+
+	fmt.Printf("\nWords per month:\n")
+	for _, m := range stats.Months {
+		fmt.Printf("  %s  %3d entries  %6d words  %5d avg\n", m.Month, m.Entries, m.Words, m.Words/m.Entries)
+	}
+
+	fmt.Println()
+}
+
+// missingRanges collapses consecutive days into ranges, e.g. [2024-01-01 2024-01-02] -> [2024-01-01 – 2024-01-02]
+func missingRanges(missing []string) []string {
+	ranges := make([]string, 0)
+
+	for i := 0; i < len(missing); {
+		j := i
+		for j+1 < len(missing) && nextDay(missing[j]) == missing[j+1] {
+			j++
+		}
+
+		if i == j {
+			ranges = append(ranges, missing[i])
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%s – %s", missing[i], missing[j]))
+		}
+
+		i = j + 1
+	}
+
+	return ranges
+}
+
+func nextDay(title string) string {
+	day, err := time.Parse(dailyTitleFormat, title)
+	if err != nil {
+		return ""
+	}
+	return day.AddDate(0, 0, 1).Format(dailyTitleFormat)
+}
+
+// heatmap renders a calendar of the range, with a row per weekday and a column per week
+func heatmap(entries []*Entry, since, until time.Time) string {
+	levels := heatmapLevelsByDay(entries)
+	start := daily.BuiltinPeriod("week").Start(since)
+
+	weeks := 0
+	for week := start; !week.After(until); week = week.AddDate(0, 0, 7) {
+		weeks++
+	}
+
+	// label the first column, and each column containing the first of a month, unless they'd overlap
+	header := []rune(strings.Repeat(" ", weeks*2+3))
+	next := 0
+	for i := 0; i < weeks; i++ {
+		week := start.AddDate(0, 0, 7*i)
+		sunday := week.AddDate(0, 0, 6)
+
+		if i == 0 && sunday.Day() > 7 {
+			copy(header[0:], []rune(week.Format("Jan")))
+			next = 4
+		} else if sunday.Day() <= 7 && 2*i >= next {
+			copy(header[2*i:], []rune(sunday.Format("Jan")))
+			next = 2*i + 4
+		}
+	}
+
+	b := strings.Builder{}
+
+	b.WriteString("    ")
+	b.WriteString(strings.TrimRight(string(header), " "))
+	b.WriteString("\n")
+
+	for row := 0; row < 7; row++ {
+		b.WriteString(start.AddDate(0, 0, row).Format("Mon"))
+		b.WriteString(" ")
+
+		for week := start; !week.After(until); week = week.AddDate(0, 0, 7) {
+			day := week.AddDate(0, 0, row)
+			if day.Before(since) || day.After(until) {
+				b.WriteString("  ")
+			} else {
+				b.WriteString(heatmapLevels[levels[day.Format(dailyTitleFormat)]])
+				b.WriteString(" ")
+			}
+		}
+
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// heatmapHTML renders a self-contained HTML report with a calendar heatmap of the range
+func heatmapHTML(stats *Stats, since, until time.Time) string {
+	levels := heatmapLevelsByDay(stats.Entries)
+	words := make(map[string]int)
+	for _, entry := range stats.Entries {
+		words[entry.Title] = entry.Words
+	}
+
+	start := daily.BuiltinPeriod("week").Start(since)
+
+	b := strings.Builder{}
+	b.WriteString(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Journal</title>`)
+	b.WriteString(`<style>body{font-family:-apple-system,sans-serif}td{width:12px;height:12px;border-radius:2px}th{font-weight:normal;font-size:10px;text-align:left}</style>`)
+	b.WriteString(`</head><body>`)
+
+	fmt.Fprintf(&b, `<h1>Journal: %s – %s</h1>`, stats.Since, stats.Until)
+	fmt.Fprintf(&b, `<p>%d entries · current streak %s · longest streak %s · %d missing days</p>`,
+		len(stats.Entries), util.Pluralize(stats.CurrentStreak, "day"), util.Pluralize(stats.LongestStreak, "day"), len(stats.Missing))
+
+	b.WriteString(`<table>`)
+	for row := 0; row < 7; row++ {
+		fmt.Fprintf(&b, `<tr><th>%s</th>`, start.AddDate(0, 0, row).Format("Mon"))
+
+		for week := start; !week.After(until); week = week.AddDate(0, 0, 7) {
+			day := week.AddDate(0, 0, row)
+			title := day.Format(dailyTitleFormat)
+
+			if day.Before(since) || day.After(until) {
+				b.WriteString(`<td></td>`)
+			} else {
+				fmt.Fprintf(&b, `<td style="background:%s" title="%s"></td>`,
+					heatmapColors[levels[title]], html.EscapeString(fmt.Sprintf("%s: %d words", title, words[title])))
+			}
+		}
+
+		b.WriteString(`</tr>`)
+	}
+	b.WriteString(`</table></body></html>`)
+
+	return b.String()
+}
+
+// heatmapLevelsByDay buckets each entry's word count into levels 1-4, relative to the longest entry
+func heatmapLevelsByDay(entries []*Entry) map[string]int {
+	most := 0
+	for _, entry := range entries {
+		most = max(most, entry.Words)
+	}
+
+	levels := make(map[string]int)
+	for _, entry := range entries {
+		level := 1
+		if most > 0 {
+			level = max(1, (entry.Words*4+most-1)/most)
+		}
+		levels[entry.Title] = level
+	}
+
+	return levels
+}
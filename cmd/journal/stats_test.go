@@ -0,0 +1,87 @@
+package journal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEntries() []*Entry {
+	return dailyEntries([]*db.Record{
+		{ID: "5", Title: "2024-03-05", Text: "one two three four"},
+		{ID: "1", Title: "2024-02-28", Text: "one"},
+		{ID: "2", Title: "2024-02-29", Text: "one two"},
+		{ID: "3", Title: "2024-03-01", Text: ""},
+		{ID: "x", Title: "2024-03-01 retro", Text: "not a daily note"},
+		{ID: "6", Title: "2024-03-06", Text: "one two"},
+	})
+}
+
+func TestDailyEntries(t *testing.T) {
+	entries := testEntries()
+
+	assert.Equal(t, 5, len(entries))
+	assert.Equal(t, "2024-02-28", entries[0].Title)
+	assert.Equal(t, "2024-03-06", entries[4].Title)
+	assert.Equal(t, 4, entries[3].Words)
+}
+
+func TestComputeStats(t *testing.T) {
+	since := time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local)
+	now := time.Date(2024, 3, 7, 9, 0, 0, 0, time.Local)
+
+	stats := computeStats(testEntries(), since, until, now)
+
+	assert.Equal(t, 4, len(stats.Entries))
+	assert.Equal(t, []string{"2024-03-02", "2024-03-03", "2024-03-04"}, stats.Missing)
+
+	// today's entry hasn't been written yet, so the streak continues from yesterday
+	assert.Equal(t, 2, stats.CurrentStreak)
+
+	assert.Equal(t, 3, stats.LongestStreak)
+	assert.Equal(t, "2024-02-28", stats.LongestStreakStart)
+	assert.Equal(t, "2024-03-01", stats.LongestStreakEnd)
+
+	assert.Equal(t, []*MonthStats{{"2024-02", 1, 2}, {"2024-03", 3, 6}}, stats.Months)
+
+	stats = computeStats(testEntries(), since, until, now.AddDate(0, 0, 2))
+	assert.Equal(t, 0, stats.CurrentStreak)
+}
+
+func TestMissingRanges(t *testing.T) {
+	missing := []string{"2024-02-28", "2024-02-29", "2024-03-01", "2024-03-03", "2024-03-05", "2024-03-06"}
+
+	assert.Equal(t, []string{"2024-02-28 – 2024-03-01", "2024-03-03", "2024-03-05 – 2024-03-06"}, missingRanges(missing))
+	assert.Empty(t, missingRanges(nil))
+}
+
+func TestHeatmap(t *testing.T) {
+	since := time.Date(2024, 2, 26, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local)
+
+	lines := strings.Split(heatmap(testEntries(), since, until), "\n")
+
+	assert.Equal(t, 9, len(lines), lines)
+	assert.Equal(t, "    Mar", lines[0])
+	assert.Equal(t, "Mon · · ", lines[1])
+	assert.Equal(t, "Tue · █ ", lines[2])
+	assert.Equal(t, "Wed ░ ▒ ", lines[3])
+	assert.Equal(t, "Thu ▒   ", lines[4])
+	assert.Equal(t, "Fri ░   ", lines[5])
+}
+
+func TestHeatmapHTML(t *testing.T) {
+	since := time.Date(2024, 2, 26, 0, 0, 0, 0, time.Local)
+	until := time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local)
+	stats := computeStats(testEntries(), since, until, until)
+
+	page := heatmapHTML(stats, since, until)
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assert.Contains(t, page, `title="2024-03-05: 4 words"`)
+	assert.Equal(t, 7, strings.Count(page, "<tr>"))
+}
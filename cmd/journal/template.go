@@ -0,0 +1,154 @@
+package journal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mnadel/freddiebear/daily"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/todo"
+	"github.com/pkg/errors"
+)
+
+const (
+	dailyTitleFormat  = daily.TitleFormat
+	dailyTitlePattern = "____-__-__"
+
+	defaultTemplate = `{{.Weekday}}, {{.ISOWeek}}
+← {{.Yesterday}} · {{.Tomorrow}} →
+{{with .Todos}}
+## Carried Forward
+{{.}}{{end}}`
+
+	defaultPeriodTemplate = `{{.Start.Format "Jan 2"}} – {{.End.Format "Jan 2, 2006"}}
+← {{.Previous}} · {{.Next}} →
+{{with .Days}}
+## Daily Notes
+{{range .}}- {{.}}
+{{end}}{{end}}`
+)
+
+// TemplateData holds the values available to a journal template
+type TemplateData struct {
+	// Title is the entry's title (2024-03-01)
+	Title string
+	// Period is the span of time the entry covers (day, week, month, quarter, year)
+	Period string
+	// Date is the entry's date, e.g. {{.Date.Format "January 2"}}
+	Date time.Time
+	// Start and End are the first and last days of the period
+	Start time.Time
+	End   time.Time
+	// Weekday is the name of the day (Friday)
+	Weekday string
+	// ISOWeek is the ISO 8601 week (2024-W09)
+	ISOWeek string
+	// Previous and Next are wikilinks to the adjacent notes of the same period ([[2024-W08]])
+	Previous string
+	Next     string
+	// Yesterday and Tomorrow are wikilinks to the adjacent daily notes ([[2024-02-29]])
+	Yesterday string
+	Tomorrow  string
+	// Days are wikilinks to the existing daily notes within the period
+	Days []string
+	// Tag is the tag added to the entry
+	Tag string
+	// Todos are the incomplete todos from the previous daily note, as a Markdown task list
+	Todos string
+}
+
+func newTemplateData(period *daily.Period, day time.Time, tag string) *TemplateData {
+	year, week := day.ISOWeek()
+
+	return &TemplateData{
+		Title:     period.Title(day),
+		Period:    period.Name,
+		Date:      day,
+		Start:     period.Start(day),
+		End:       period.End(day).AddDate(0, 0, -1),
+		Weekday:   day.Weekday().String(),
+		ISOWeek:   fmt.Sprintf("%d-W%02d", year, week),
+		Previous:  wikilink(period.Title(period.Previous(day))),
+		Next:      wikilink(period.Title(period.End(day))),
+		Yesterday: wikilink(day.AddDate(0, 0, -1).Format(dailyTitleFormat)),
+		Tomorrow:  wikilink(day.AddDate(0, 0, 1).Format(dailyTitleFormat)),
+		Tag:       tag,
+		Days:      make([]string, 0),
+	}
+}
+
+// carryForward adds the incomplete todos from the previous note
+func (data *TemplateData) carryForward(previous *db.Record, bearSyntax bool) {
+	if previous != nil {
+		data.Todos = todo.Markdown(todo.Incomplete(todo.Parse([]byte(previous.Text), bearSyntax)))
+	}
+}
+
+// addDays adds links to the daily notes (given their titles) that fall within the period
+func (data *TemplateData) addDays(titles []string) {
+	sorted := make([]string, len(titles))
+	copy(sorted, titles)
+	sort.Strings(sorted)
+
+	first, last := data.Start.Format(dailyTitleFormat), data.End.Format(dailyTitleFormat)
+
+	for _, title := range sorted {
+		if _, err := time.Parse(dailyTitleFormat, title); err == nil && title >= first && title <= last {
+			data.Days = append(data.Days, wikilink(title))
+		}
+	}
+}
+
+// renderTemplate renders the template file, or the default template if filename is empty
+func renderTemplate(filename string, data *TemplateData) (string, error) {
+	source := defaultTemplate
+	if data.Period != "day" {
+		source = defaultPeriodTemplate
+	}
+
+	if filename != "" {
+		contents, err := os.ReadFile(filename)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		source = string(contents)
+	}
+
+	tmpl, err := template.New("journal").Parse(source)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid template %s", filename)
+	}
+
+	b := strings.Builder{}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return b.String(), nil
+}
+
+// previousDailyNote returns the most recent daily note before the given day, or nil
+func previousDailyNote(records []*db.Record, day time.Time) *db.Record {
+	today := day.Format(dailyTitleFormat)
+	var previous *db.Record
+
+	for _, record := range records {
+		if _, err := time.Parse(dailyTitleFormat, record.Title); err != nil {
+			continue
+		}
+
+		if record.Title < today && (previous == nil || record.Title > previous.Title) {
+			previous = record
+		}
+	}
+
+	return previous
+}
+
+func wikilink(title string) string {
+	return "[[" + title + "]]"
+}
@@ -0,0 +1,84 @@
+package journal
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/daily"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTemplateData(t *testing.T) {
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	previous := &db.Record{Title: "2024-02-28", Text: "# 2024-02-28\n- [ ] carry me\n- [x] leave me\n"}
+
+	data := newTemplateData(daily.BuiltinPeriod("day"), day, "journal")
+	data.carryForward(previous, false)
+
+	assert.Equal(t, "2024-03-01", data.Title)
+	assert.Equal(t, "Friday", data.Weekday)
+	assert.Equal(t, "2024-W09", data.ISOWeek)
+	assert.Equal(t, "[[2024-02-29]]", data.Yesterday)
+	assert.Equal(t, "[[2024-03-02]]", data.Tomorrow)
+	assert.Equal(t, "- [ ] carry me\n", data.Todos)
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	day := time.Date(2024, 1, 1, 9, 0, 0, 0, time.Local)
+
+	body, err := renderTemplate("", newTemplateData(daily.BuiltinPeriod("day"), day, ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "Monday, 2024-W01\n← [[2023-12-31]] · [[2024-01-02]] →\n", body)
+
+	data := newTemplateData(daily.BuiltinPeriod("day"), day, "")
+	data.carryForward(&db.Record{Text: "- [ ] a"}, false)
+
+	body, err = renderTemplate("", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Monday, 2024-W01\n← [[2023-12-31]] · [[2024-01-02]] →\n\n## Carried Forward\n- [ ] a\n", body)
+}
+
+func TestRenderTemplateFile(t *testing.T) {
+	filename := path.Join(t.TempDir(), "monday.md")
+	assert.NoError(t, os.WriteFile(filename, []byte(`{{.Date.Format "January 2"}} planning #{{.Tag}}`), 0644))
+
+	day := time.Date(2024, 1, 1, 9, 0, 0, 0, time.Local)
+	body, err := renderTemplate(filename, newTemplateData(daily.BuiltinPeriod("day"), day, "plans"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "January 1 planning #plans", body)
+}
+
+func TestRenderWeeklyTemplate(t *testing.T) {
+	day := time.Date(2024, 2, 14, 9, 0, 0, 0, time.Local)
+
+	data := newTemplateData(daily.BuiltinPeriod("week"), day, "")
+	data.addDays([]string{"2024-02-18", "2024-02-12", "2024-02-19", "2024-02-11", "2024-02-13 notes"})
+
+	assert.Equal(t, "2024-W07", data.Title)
+	assert.Equal(t, "[[2024-W06]]", data.Previous)
+	assert.Equal(t, "[[2024-W08]]", data.Next)
+	assert.Equal(t, []string{"[[2024-02-12]]", "[[2024-02-18]]"}, data.Days)
+
+	body, err := renderTemplate("", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Feb 12 – Feb 18, 2024\n← [[2024-W06]] · [[2024-W08]] →\n\n## Daily Notes\n- [[2024-02-12]]\n- [[2024-02-18]]\n", body)
+}
+
+func TestPreviousDailyNote(t *testing.T) {
+	records := []*db.Record{
+		{Title: "2024-03-02"},
+		{Title: "2024-02-27"},
+		{Title: "2024-02-28"},
+		{Title: "abcd-ef-gh"},
+	}
+
+	day := time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local)
+	assert.Equal(t, "2024-02-28", previousDailyNote(records, day).Title)
+
+	day = time.Date(2024, 1, 1, 9, 0, 0, 0, time.Local)
+	assert.Nil(t, previousDailyNote(records, day))
+}
@@ -0,0 +1,140 @@
+package recent
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/db/exporter"
+)
+
+const (
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	summaryLength = 280
+)
+
+// Feed is an Atom feed of recent notes
+type Feed struct {
+	Title string
+	// BaseURL, if set, is where exported notes are published: entries link to their exported file
+	// beneath it, rather than to the note in Bear
+	BaseURL string
+	Updated time.Time
+	Entries []*Entry
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Link       atomLink       `xml:"link"`
+	Categories []atomCategory `xml:"category"`
+	Summary    string         `xml:"summary,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// writeFeed writes the feed in Atom format
+func writeFeed(w io.Writer, feed *Feed) error {
+	out := atomFeed{
+		XMLNS:   atomNamespace,
+		ID:      "urn:freddiebear:recent",
+		Title:   feed.Title,
+		Updated: feed.Updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: "freddiebear"},
+		Entries: make([]atomEntry, 0, len(feed.Entries)),
+	}
+
+	if feed.BaseURL != "" {
+		out.ID = feed.BaseURL
+		out.Link = &atomLink{Href: feed.BaseURL}
+	}
+
+	for _, e := range feed.Entries {
+		entry := atomEntry{
+			ID:        "urn:bear-note:" + e.ID,
+			Title:     e.Title,
+			Updated:   e.Modified.Format(time.RFC3339),
+			Published: e.Created.Format(time.RFC3339),
+			Link:      atomLink{Href: entryLink(feed.BaseURL, e), Rel: "alternate"},
+			Summary:   summary(e.Text, e.Title),
+		}
+
+		for _, tag := range e.Tags {
+			entry.Categories = append(entry.Categories, atomCategory{Term: tag})
+		}
+
+		out.Entries = append(out.Entries, entry)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// entryLink links to the note's exported file beneath the base URL, or else to the note in Bear
+func entryLink(baseURL string, e *Entry) string {
+	if baseURL == "" {
+		return bearurl.OpenNote{ID: e.ID}.String()
+	}
+
+	filename := exporter.BuildFilename(&db.Record{Title: e.Title, SHA: db.NoteSHA(e.ID)})
+
+	return strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(filename)
+}
+
+// summary is the start of the note's text, without its title
+func summary(text, title string) string {
+	lines := strings.SplitN(text, "\n", 2)
+	if strings.TrimLeft(lines[0], "# ") == title {
+		if len(lines) < 2 {
+			return ""
+		}
+		text = lines[1]
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) > summaryLength {
+		return strings.TrimSpace(string(runes[:summaryLength])) + "…"
+	}
+
+	return text
+}
@@ -0,0 +1,260 @@
+package recent
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const dateFormat = "2006-01-02 15:04:05"
+
+// By is the date that notes are selected and ordered by
+type By string
+
+const (
+	Modified By = "modified"
+	Created  By = "created"
+)
+
+var (
+	optSince   string
+	optBy      string
+	optFormat  string
+	optFeed    string
+	optBaseURL string
+	optLimit   int
+)
+
+// Entry is a recently modified or created note
+type Entry struct {
+	ID       string
+	Title    string
+	Tags     []string
+	Text     string
+	Created  time.Time
+	Modified time.Time
+	// When is the date the note was selected by
+	When time.Time
+}
+
+// Day is the notes from a single day, newest first
+type Day struct {
+	Date    string
+	Entries []*Entry
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "Show recently modified or created notes",
+		Long:  "List the notes modified (or created) recently, newest first, as Alfred items or grouped by day, or write them to an Atom feed",
+		Args:  cobra.NoArgs,
+		RunE:  runner,
+	}
+
+	cmd.Flags().StringVar(&optSince, "since", "24h", "how far back to look, e.g. 24h, 7d or 2w")
+	cmd.Flags().StringVar(&optBy, "by", string(Modified), "modified or created")
+	cmd.Flags().StringVar(&optFormat, "format", "alfred", "output format (alfred, text)")
+	cmd.Flags().StringVar(&optFeed, "feed", "", "write an Atom feed to this file instead")
+	cmd.Flags().StringVar(&optBaseURL, "base-url", "", "link feed entries to exported notes beneath this URL, rather than to Bear")
+	cmd.Flags().IntVar(&optLimit, "limit", 0, "only show this many notes (default: all)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	by := By(optBy)
+	if by != Modified && by != Created {
+		return fmt.Errorf("unknown date: %s", optBy)
+	}
+
+	age, err := util.ParseAge(optSince)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	notes, err := bearDB.NoteStats()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := time.Now()
+
+	entries := util.Limit(collect(cfg, notes, by, now.Add(-age)), optLimit)
+
+	if optFeed != "" {
+		f, err := os.Create(optFeed)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+
+		return errors.WithStack(writeFeed(f, &Feed{
+			Title:   feedTitle(by),
+			BaseURL: optBaseURL,
+			Updated: now,
+			Entries: entries,
+		}))
+	}
+
+	switch optFormat {
+	case "alfred":
+		output, err := alfred.AlfredJSON(alfredItems(entries, by, now))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(output)
+	case "text":
+		writeText(os.Stdout, groupByDay(entries))
+	default:
+		return fmt.Errorf("unknown format: %s", optFormat)
+	}
+
+	return nil
+}
+
+// collect returns the active notes modified (or created) since the cutoff, newest first
+func collect(cfg *config.Config, notes []*db.NoteStat, by By, cutoff time.Time) []*Entry {
+	entries := make([]*Entry, 0)
+
+	for _, n := range notes {
+		if n.Trashed || n.Archived || cfg.ExcludesNote(n.Title, n.Tags) {
+			continue
+		}
+
+		created, _ := time.ParseInLocation(dateFormat, n.Created, cutoff.Location())
+		modified, _ := time.ParseInLocation(dateFormat, n.Modified, cutoff.Location())
+
+		entry := &Entry{
+			ID:       n.ID,
+			Title:    n.Title,
+			Tags:     n.Tags,
+			Text:     n.Text,
+			Created:  created,
+			Modified: modified,
+			When:     modified,
+		}
+		if by == Created {
+			entry.When = created
+		}
+
+		if entry.When.Before(cutoff) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].When.After(entries[j].When)
+	})
+
+	return entries
+}
+
+// groupByDay groups the entries, which are newest first, by the day of their date
+func groupByDay(entries []*Entry) []*Day {
+	days := make([]*Day, 0)
+
+	for _, e := range entries {
+		date := e.When.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, &Day{Date: date})
+		}
+		day := days[len(days)-1]
+		day.Entries = append(day.Entries, e)
+	}
+
+	return days
+}
+
+// relativeTime describes how long before now t was, e.g. 5 minutes ago or yesterday
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d.Minutes()), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d.Hours()), "hour")
+	case d < 48*time.Hour:
+		return "yesterday"
+	case d < 14*24*time.Hour:
+		return plural(int(d.Hours()/24), "day")
+	default:
+		return plural(int(d.Hours()/24/7), "week")
+	}
+}
+
+func alfredItems(entries []*Entry, by By, now time.Time) []*alfred.Item {
+	items := make([]*alfred.Item, 0, len(entries))
+
+	for _, e := range entries {
+		subtitle := fmt.Sprintf("%s %s", by, relativeTime(e.When, now))
+		if len(e.Tags) > 0 {
+			subtitle += " · " + strings.Join(util.RemoveIntermediatePrefixes(append([]string{}, e.Tags...), "/"), ", ")
+		}
+
+		items = append(items, &alfred.Item{
+			UID:      e.ID,
+			Title:    e.Title,
+			Subtitle: subtitle,
+			Arg:      e.ID,
+			Valid:    true,
+		})
+	}
+
+	return items
+}
+
+func writeText(w io.Writer, days []*Day) {
+	for i, day := range days {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+
+		date, _ := time.Parse("2006-01-02", day.Date)
+		fmt.Fprintf(w, "## %s (%s)\n", day.Date, date.Weekday())
+
+		for _, e := range day.Entries {
+			fmt.Fprintf(w, "- %s %s\n", e.When.Format("15:04"), e.Title)
+		}
+	}
+}
+
+func feedTitle(by By) string {
+	if by == Created {
+		return "Recently created notes"
+	}
+	return "Recently modified notes"
+}
@@ -0,0 +1,119 @@
+package recent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)
+
+func testNotes() []*db.NoteStat {
+	return []*db.NoteStat{
+		{ID: "a", Title: "Standup", Created: "2024-03-01 09:00:00", Modified: "2024-03-15 11:30:00", Text: "# Standup\nShipped it", Tags: []string{"work", "work/meetings"}},
+		{ID: "b", Title: "Groceries", Created: "2024-03-14 18:00:00", Modified: "2024-03-14 18:05:00", Text: "milk"},
+		{ID: "c", Title: "Old", Created: "2024-01-01 09:00:00", Modified: "2024-02-01 09:00:00"},
+		{ID: "d", Title: "Trashed", Created: "2024-03-15 10:00:00", Modified: "2024-03-15 10:00:00", Trashed: true},
+		{ID: "e", Title: "Diary", Created: "2024-03-15 08:00:00", Modified: "2024-03-15 08:00:00", Tags: []string{"private"}},
+		{ID: "f", Title: "Plans", Created: "2024-03-15 07:00:00", Modified: "2024-03-15 09:00:00"},
+	}
+}
+
+func ids(entries []*Entry) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.ID)
+	}
+	return result
+}
+
+func TestCollect(t *testing.T) {
+	cfg := &config.Config{Exclude: config.Exclude{Tags: []string{"private"}}}
+
+	assert.Equal(t, []string{"a", "f", "b"}, ids(collect(cfg, testNotes(), Modified, now.Add(-24*time.Hour))))
+	assert.Equal(t, []string{"a", "f"}, ids(collect(cfg, testNotes(), Modified, now.Add(-6*time.Hour))))
+	assert.Equal(t, []string{"f", "b"}, ids(collect(cfg, testNotes(), Created, now.Add(-24*time.Hour))))
+}
+
+func TestGroupByDay(t *testing.T) {
+	days := groupByDay(collect(&config.Config{}, testNotes(), Modified, now.Add(-7*24*time.Hour)))
+
+	assert.Len(t, days, 2)
+	assert.Equal(t, "2024-03-15", days[0].Date)
+	assert.Equal(t, []string{"a", "f", "e"}, ids(days[0].Entries))
+	assert.Equal(t, "2024-03-14", days[1].Date)
+
+	var buf bytes.Buffer
+	writeText(&buf, days)
+	assert.Equal(t, "## 2024-03-15 (Friday)\n- 11:30 Standup\n- 09:00 Plans\n- 08:00 Diary\n\n## 2024-03-14 (Thursday)\n- 18:05 Groceries\n", buf.String())
+}
+
+func TestRelativeTime(t *testing.T) {
+	tests := map[time.Duration]string{
+		10 * time.Second:    "just now",
+		time.Minute:         "1 minute ago",
+		45 * time.Minute:    "45 minutes ago",
+		3 * time.Hour:       "3 hours ago",
+		30 * time.Hour:      "yesterday",
+		5 * 24 * time.Hour:  "5 days ago",
+		21 * 24 * time.Hour: "3 weeks ago",
+	}
+
+	for ago, expected := range tests {
+		assert.Equal(t, expected, relativeTime(now.Add(-ago), now), ago.String())
+	}
+}
+
+func TestAlfredItems(t *testing.T) {
+	items := alfredItems(collect(&config.Config{}, testNotes(), Modified, now.Add(-24*time.Hour)), Modified, now)
+
+	assert.Equal(t, "Standup", items[0].Title)
+	assert.Equal(t, "modified 30 minutes ago · work/meetings", items[0].Subtitle)
+	assert.Equal(t, "a", items[0].Arg)
+	assert.Equal(t, "modified 17 hours ago", items[len(items)-1].Subtitle)
+}
+
+func TestWriteFeed(t *testing.T) {
+	entries := collect(&config.Config{}, testNotes(), Modified, now.Add(-24*time.Hour))
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeFeed(&buf, &Feed{Title: "Recent", Updated: now, Entries: entries}))
+
+	assert.True(t, strings.HasPrefix(buf.String(), xml.Header))
+
+	var feed atomFeed
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &feed))
+
+	assert.Equal(t, "Recent", feed.Title)
+	assert.Len(t, feed.Entries, 4)
+
+	entry := feed.Entries[0]
+	assert.Equal(t, "urn:bear-note:a", entry.ID)
+	assert.Equal(t, "Standup", entry.Title)
+	assert.Equal(t, now.Add(-30*time.Minute).Format(time.RFC3339), entry.Updated)
+	assert.Equal(t, "bear://x-callback-url/open-note?id=a", entry.Link.Href)
+	assert.Equal(t, "Shipped it", entry.Summary)
+	assert.Equal(t, []atomCategory{{Term: "work"}, {Term: "work/meetings"}}, entry.Categories)
+}
+
+func TestEntryLink(t *testing.T) {
+	link := entryLink("https://notes.example.com/export/", &Entry{ID: "a", Title: "Q2/Q3 plans"})
+
+	assert.Equal(t, "https://notes.example.com/export/Q2%252FQ3%20plans%20%28"+db.NoteSHA("a")+"%29.md", link)
+}
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "Body text here", summary("# Title\nBody\n\ntext   here", "Title"))
+	assert.Equal(t, "", summary("# Title", "Title"))
+	assert.Equal(t, "No title line", summary("No title line", "Other"))
+
+	long := summary("# T\n"+strings.Repeat("word ", 100), "T")
+	assert.True(t, strings.HasSuffix(long, "…"))
+	assert.LessOrEqual(t, len([]rune(long)), summaryLength+1)
+}
@@ -0,0 +1,227 @@
+package related
+
+import (
+	"encoding/gob"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+)
+
+// indexVersion changes whenever the way notes are tokenized does, invalidating cached indexes
+const indexVersion = 1
+
+var stopwords = func() map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.Fields(`
+		about after again also among and any are aren because been before being between both but can cannot
+		could did didn does doesn doing don down during each few for from further had hadn has hasn have haven
+		having her here hers herself him himself his how http https into its itself just let like more most
+		much must myself nor not now off once only other our ours ourselves out over own same she should so
+		some such than that the their theirs them themselves then there these they this those through too
+		under until very was wasn way were weren what when where which while who whom why will with won would
+		wouldn www you your yours yourself yourselves com
+	`) {
+		words[w] = true
+	}
+	return words
+}()
+
+// Doc is a note's term frequencies, as of its modification date
+type Doc struct {
+	ID       string
+	Title    string
+	Tags     string
+	Modified string
+	Terms    map[string]int
+}
+
+// Index is the term frequencies of every note, which is cached on disk so that only the notes
+// modified since it was built need to be tokenized again
+type Index struct {
+	Version int
+	Docs    map[string]*Doc
+}
+
+// Match is a note that's similar to another
+type Match struct {
+	Doc   *Doc
+	Score float64
+}
+
+// Tokenize returns the text's lowercased words, without stopwords, numbers, or words shorter than three letters
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if len([]rune(f)) < 3 || stopwords[f] || strings.IndexFunc(f, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, f)
+	}
+
+	return tokens
+}
+
+// LoadIndex reads a cached index, returning an empty index if there isn't one or it's out of date
+func LoadIndex(path string) *Index {
+	index := &Index{Version: indexVersion, Docs: make(map[string]*Doc)}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return index
+	}
+	defer f.Close()
+
+	cached := &Index{}
+	if err := gob.NewDecoder(f).Decode(cached); err != nil || cached.Version != indexVersion || cached.Docs == nil {
+		return index
+	}
+
+	return cached
+}
+
+// Save writes the index to disk
+func (idx *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return errors.WithStack(err)
+	}
+
+	tmp := path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := gob.NewEncoder(f).Encode(idx); err != nil {
+		f.Close()
+		return errors.WithStack(err)
+	}
+
+	if err := f.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(os.Rename(tmp, path))
+}
+
+// Update brings the index up to date with the records, tokenizing those that are new or have been
+// modified and dropping those that no longer exist. It returns true if anything changed.
+func (idx *Index) Update(records []*db.Record) bool {
+	changed := false
+	seen := make(map[string]bool, len(records))
+
+	for _, record := range records {
+		seen[record.ID] = true
+
+		if doc, ok := idx.Docs[record.ID]; ok && doc.Modified == record.ModificationDate {
+			if doc.Title != record.Title || doc.Tags != record.Tags {
+				doc.Title, doc.Tags = record.Title, record.Tags
+				changed = true
+			}
+			continue
+		}
+
+		terms := make(map[string]int)
+		for _, token := range Tokenize(record.Text) {
+			terms[token]++
+		}
+
+		idx.Docs[record.ID] = &Doc{
+			ID:       record.ID,
+			Title:    record.Title,
+			Tags:     record.Tags,
+			Modified: record.ModificationDate,
+			Terms:    terms,
+		}
+		changed = true
+	}
+
+	for id := range idx.Docs {
+		if !seen[id] {
+			delete(idx.Docs, id)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// Similar returns the notes most similar to the note with the ID, by the cosine similarity of their
+// TF-IDF vectors, best first. Notes for which skip returns true aren't included.
+func (idx *Index) Similar(id string, limit int, skip func(*Doc) bool) []*Match {
+	target, ok := idx.Docs[id]
+	if !ok {
+		return nil
+	}
+
+	df := make(map[string]int)
+	for _, doc := range idx.Docs {
+		for term := range doc.Terms {
+			df[term]++
+		}
+	}
+
+	n := float64(len(idx.Docs))
+	weigh := func(doc *Doc) (map[string]float64, float64) {
+		vector := make(map[string]float64, len(doc.Terms))
+		norm := 0.0
+		for term, tf := range doc.Terms {
+			w := (1 + math.Log(float64(tf))) * math.Log(n/float64(df[term]))
+			if w > 0 {
+				vector[term] = w
+				norm += w * w
+			}
+		}
+		return vector, math.Sqrt(norm)
+	}
+
+	targetVector, targetNorm := weigh(target)
+	if targetNorm == 0 {
+		return nil
+	}
+
+	matches := make([]*Match, 0)
+
+	for _, doc := range idx.Docs {
+		if doc.ID == id || (skip != nil && skip(doc)) {
+			continue
+		}
+
+		vector, norm := weigh(doc)
+		if norm == 0 {
+			continue
+		}
+
+		dot := 0.0
+		for term, w := range targetVector {
+			dot += w * vector[term]
+		}
+
+		if dot > 0 {
+			matches = append(matches, &Match{Doc: doc, Score: dot / (targetNorm * norm)})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Doc.Title < matches[j].Doc.Title
+	})
+
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
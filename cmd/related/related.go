@@ -0,0 +1,184 @@
+package related
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// CacheFile is where the index is cached, relative to the user's cache directory
+const CacheFile = "freddiebear/related.gob"
+
+var (
+	optLimit        int
+	optSuggestLinks bool
+	optFormat       string
+	optCache        string
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "related <note>",
+		Short: "Find notes related to a note",
+		Long: `Find the notes most similar to a note (given by its ID or title), by the TF-IDF similarity of their text,
+leaving out notes it already links to or is linked from.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runner,
+	}
+
+	cmd.Flags().IntVar(&optLimit, "limit", 10, "number of notes to show")
+	cmd.Flags().BoolVar(&optSuggestLinks, "suggest-links", false, "propose [[wikilinks]] to add to the note")
+	cmd.Flags().StringVar(&optFormat, "format", "alfred", "output format (alfred, text)")
+	cmd.Flags().StringVar(&optCache, "cache", "", "index cache file (default: freddiebear/related.gob in your cache directory)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cache := optCache
+	if cache == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		cache = filepath.Join(dir, CacheFile)
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	records, err := bearDB.Records()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	graph, err := bearDB.QueryGraph()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	index := LoadIndex(cache)
+	if index.Update(records) {
+		if err := index.Save(cache); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	note, err := findNote(index, args[0])
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	links := linked(graph, note.ID)
+	matches := index.Similar(note.ID, optLimit, func(doc *Doc) bool {
+		return links[doc.ID] || cfg.ExcludesNote(doc.Title, strings.Split(doc.Tags, ","))
+	})
+
+	switch optFormat {
+	case "alfred":
+		output, err := alfred.AlfredJSON(alfredItems(matches, optSuggestLinks))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(output)
+	case "text":
+		writeText(os.Stdout, matches, optSuggestLinks)
+	default:
+		return fmt.Errorf("unknown format: %s", optFormat)
+	}
+
+	return nil
+}
+
+// findNote finds the note with the ID, or else the most recently modified note with the title
+func findNote(index *Index, s string) (*Doc, error) {
+	if doc, ok := index.Docs[s]; ok {
+		return doc, nil
+	}
+
+	var found *Doc
+	for _, doc := range index.Docs {
+		if strings.EqualFold(doc.Title, s) && (found == nil || doc.Modified > found.Modified) {
+			found = doc
+		}
+	}
+
+	if found == nil {
+		return nil, errors.Errorf("no note with the ID or title: %s", s)
+	}
+
+	return found, nil
+}
+
+// linked returns the IDs of the notes that the note links to or is linked from
+func linked(graph db.Graph, id string) map[string]bool {
+	links := make(map[string]bool)
+
+	for _, edge := range graph {
+		if edge.Source.ID == id {
+			links[edge.Target.ID] = true
+		}
+		if edge.Target.ID == id {
+			links[edge.Source.ID] = true
+		}
+	}
+
+	return links
+}
+
+func wikilink(title string) string {
+	return "[[" + title + "]]"
+}
+
+func alfredItems(matches []*Match, suggestLinks bool) []*alfred.Item {
+	items := make([]*alfred.Item, 0, len(matches))
+
+	for _, m := range matches {
+		item := &alfred.Item{
+			UID:      m.Doc.ID,
+			Title:    m.Doc.Title,
+			Subtitle: fmt.Sprintf("%.0f%% similar", m.Score*100),
+			Arg:      m.Doc.ID,
+			Valid:    true,
+		}
+
+		if m.Doc.Tags != "" {
+			item.Subtitle += " · " + strings.Join((&db.Result{Tags: m.Doc.Tags}).UniqueTags(), ", ")
+		}
+
+		if suggestLinks {
+			item.Title = wikilink(m.Doc.Title)
+			item.Arg = wikilink(m.Doc.Title)
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func writeText(w io.Writer, matches []*Match, suggestLinks bool) {
+	for _, m := range matches {
+		if suggestLinks {
+			fmt.Fprintln(w, wikilink(m.Doc.Title))
+		} else {
+			fmt.Fprintf(w, "%.2f\t%s\t%s\n", m.Score, m.Doc.Title, m.Doc.ID)
+		}
+	}
+}
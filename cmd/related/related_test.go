@@ -0,0 +1,127 @@
+package related
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRecords() []*db.Record {
+	return []*db.Record{
+		{ID: "sourdough", Title: "Sourdough", Tags: "cooking", ModificationDate: "2024-01-01 10:00:00",
+			Text: "# Sourdough\nFeed the starter, then mix flour, water and salt. Bake the loaf in a dutch oven."},
+		{ID: "baguette", Title: "Baguette", Tags: "cooking,cooking/bread", ModificationDate: "2024-01-02 10:00:00",
+			Text: "# Baguette\nMix flour, water, yeast and salt. Shape the dough and bake the loaf with steam."},
+		{ID: "pizza", Title: "Pizza", Tags: "cooking", ModificationDate: "2024-01-03 10:00:00",
+			Text: "# Pizza\nA dough of flour, water and salt. Bake in a hot oven with tomato and cheese."},
+		{ID: "standup", Title: "Standup", Tags: "work", ModificationDate: "2024-01-04 10:00:00",
+			Text: "# Standup\nShipped the release, reviewed the roadmap, and planned the sprint."},
+		{ID: "retro", Title: "Retro", Tags: "work", ModificationDate: "2024-01-05 10:00:00",
+			Text: "# Retro\nThe sprint went well; the release slipped a day. Roadmap review next week."},
+	}
+}
+
+func ids(matches []*Match) []string {
+	result := make([]string, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, m.Doc.ID)
+	}
+	return result
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t,
+		[]string{"feed", "starter", "crème", "fraîche", "see", "links"},
+		Tokenize("Feed the **starter** at 9:30 on 2024-01-01 with crème-fraîche. See [[links]]!"),
+	)
+}
+
+func TestSimilar(t *testing.T) {
+	index := LoadIndex(filepath.Join(t.TempDir(), "missing.gob"))
+	index.Update(testRecords())
+
+	matches := index.Similar("sourdough", 10, nil)
+
+	assert.Equal(t, []string{"baguette", "pizza"}, ids(matches)[:2])
+	assert.NotContains(t, ids(matches), "sourdough")
+	for _, m := range matches {
+		assert.Greater(t, m.Score, 0.0)
+		assert.LessOrEqual(t, m.Score, 1.0)
+	}
+
+	assert.Equal(t, []string{"retro"}, ids(index.Similar("standup", 1, nil)))
+
+	skipped := index.Similar("sourdough", 10, func(doc *Doc) bool { return doc.ID == "baguette" })
+	assert.Equal(t, "pizza", skipped[0].Doc.ID)
+
+	assert.Nil(t, index.Similar("missing", 10, nil))
+}
+
+func TestIndexCache(t *testing.T) {
+	cache := filepath.Join(t.TempDir(), "cache", "related.gob")
+	records := testRecords()
+
+	index := LoadIndex(cache)
+	assert.True(t, index.Update(records))
+	assert.NoError(t, index.Save(cache))
+
+	cached := LoadIndex(cache)
+	assert.Len(t, cached.Docs, len(records))
+	assert.False(t, cached.Update(records), "nothing has changed")
+
+	// a modified note is tokenized again; a deleted one is dropped
+	records[0].Text = "# Sourdough\nNothing about bread anymore: quarterly roadmap."
+	records[0].ModificationDate = "2024-02-01 10:00:00"
+	records = records[:len(records)-1]
+
+	assert.True(t, cached.Update(records))
+	assert.Len(t, cached.Docs, len(records))
+	assert.Equal(t, 1, cached.Docs["sourdough"].Terms["quarterly"])
+	assert.NotContains(t, cached.Docs, "retro")
+
+	// a renamed note keeps its terms
+	records[1].Title = "French bread"
+	assert.True(t, cached.Update(records))
+	assert.Equal(t, "French bread", cached.Docs["baguette"].Title)
+}
+
+func TestFindNote(t *testing.T) {
+	index := LoadIndex("")
+	index.Update(testRecords())
+
+	doc, err := findNote(index, "pizza")
+	assert.NoError(t, err)
+	assert.Equal(t, "pizza", doc.ID)
+
+	doc, err = findNote(index, "STANDUP")
+	assert.NoError(t, err)
+	assert.Equal(t, "standup", doc.ID)
+
+	_, err = findNote(index, "nope")
+	assert.Error(t, err)
+}
+
+func TestLinked(t *testing.T) {
+	graph := db.Graph{
+		{Source: &db.Result{ID: "a"}, Target: &db.Result{ID: "b"}},
+		{Source: &db.Result{ID: "c"}, Target: &db.Result{ID: "a"}},
+		{Source: &db.Result{ID: "c"}, Target: &db.Result{ID: "d"}},
+	}
+
+	assert.Equal(t, map[string]bool{"b": true, "c": true}, linked(graph, "a"))
+}
+
+func TestAlfredItems(t *testing.T) {
+	matches := []*Match{{Doc: &Doc{ID: "baguette", Title: "Baguette", Tags: "cooking,cooking/bread"}, Score: 0.4242}}
+
+	items := alfredItems(matches, false)
+	assert.Equal(t, "Baguette", items[0].Title)
+	assert.Equal(t, "42% similar · cooking/bread", items[0].Subtitle)
+	assert.Equal(t, "baguette", items[0].Arg)
+
+	items = alfredItems(matches, true)
+	assert.Equal(t, "[[Baguette]]", items[0].Title)
+	assert.Equal(t, "[[Baguette]]", items[0].Arg)
+}
@@ -2,57 +2,166 @@ package search
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// Field is the part of a note that search looks for the term in
+type Field string
+
+const (
+	TitleField       Field = "title"
+	BodyField        Field = "body"
+	AttachmentsField Field = "attachments"
+)
+
 var (
-	optAll      bool
-	optShowTags bool
+	optAll              bool
+	optShowTags         bool
+	optAttachments      bool
+	optScope            string
+	optExcludeEncrypted bool
 )
 
 func New() *cobra.Command {
 	searchCmd := &cobra.Command{
 		Use:   "search [term]",
 		Short: "Search for a note",
-		Long:  "Generate search results in Alfred Workflow's XML schema format",
-		Args:  cobra.ExactArgs(1),
-		RunE:  runner,
+		Long: `Generate search results in Alfred Workflow's XML schema format.
+
+The term may include in:title, in:body or in:attachments to choose where to search, overriding the flags.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runner,
 	}
 
 	searchCmd.Flags().BoolVar(&optAll, "all", false, "full text search (default: titles only)")
 	searchCmd.Flags().BoolVar(&optShowTags, "show-tags", false, "include tags in output")
+	searchCmd.Flags().BoolVar(&optAttachments, "attachments", false, "search the text of attachments (e.g. scanned images and PDFs)")
+	searchCmd.Flags().StringVar(&optScope, "scope", string(db.Active), "notes to include: "+db.ScopeNames())
+	searchCmd.Flags().BoolVar(&optExcludeEncrypted, "exclude-encrypted", false, "skip encrypted (and locked) notes")
 
 	return searchCmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
+	scope, err := db.ParseScope(optScope)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	defer bearDB.Close()
 
-	var results db.Results
+	bearDB.SetScope(scope)
+	bearDB.SetExcludeEncrypted(optExcludeEncrypted)
 
+	field := TitleField
 	if optAll {
-		results, err = bearDB.QueryText(args[0])
+		field = BodyField
+	}
+	if optAttachments {
+		field = AttachmentsField
+	}
+
+	term, field := parseQuery(args[0], field)
+
+	// a query of only filters would otherwise match everything
+	if term == "" {
+		fmt.Print(alfred.AlfredEmptyXML())
+		return nil
+	}
+
+	if field == AttachmentsField {
+		matches, err := bearDB.QueryAttachmentText(term)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		matches = filterMatches(cfg, matches)
+
+		if len(matches) == 0 {
+			fmt.Print(alfred.AlfredCreateXML(term))
+		} else {
+			fmt.Print(alfred.AlfredAttachmentXML(matches, term))
+		}
+
+		return nil
+	}
+
+	var results db.Results
+
+	if field == BodyField {
+		results, err = bearDB.QueryText(term)
 	} else {
-		results, err = bearDB.QueryTitles(args[0], false)
+		results, err = bearDB.QueryTitles(term, false)
 	}
 
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	results = cfg.FilterResults(results)
+
 	if len(results) == 0 {
-		fmt.Print(alfred.AlfredCreateXML(args[0]))
+		fmt.Print(alfred.AlfredCreateXML(term))
 	} else {
 		fmt.Print(alfred.AlfredOpenXML(results, optShowTags))
 	}
 
 	return nil
 }
+
+// parseQuery removes any `in:<field>` filter from the query, returning the remaining term and the
+// field to search (the last filter wins, defaulting to the given field)
+func parseQuery(query string, field Field) (string, Field) {
+	words := make([]string, 0)
+
+	for _, word := range strings.Fields(query) {
+		if lower := strings.ToLower(word); strings.HasPrefix(lower, "in:") {
+			switch filter := Field(strings.TrimPrefix(lower, "in:")); filter {
+			case TitleField, BodyField, AttachmentsField:
+				field = filter
+				continue
+			}
+		}
+
+		words = append(words, word)
+	}
+
+	return strings.Join(words, " "), field
+}
+
+// filterMatches removes matches from excluded notes
+func filterMatches(cfg *config.Config, matches []*db.AttachmentMatch) []*db.AttachmentMatch {
+	notes := make(db.Results, 0, len(matches))
+	for _, match := range matches {
+		notes = append(notes, match.Note)
+	}
+
+	included := make(map[*db.Result]bool)
+	for _, note := range cfg.FilterResults(notes) {
+		included[note] = true
+	}
+
+	filtered := make([]*db.AttachmentMatch, 0, len(matches))
+	for _, match := range matches {
+		if included[match.Note] {
+			filtered = append(filtered, match)
+		}
+	}
+
+	return filtered
+}
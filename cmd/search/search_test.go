@@ -0,0 +1,46 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuery(t *testing.T) {
+	term, field := parseQuery("whiteboard in:attachments", TitleField)
+	assert.Equal(t, "whiteboard", term)
+	assert.Equal(t, AttachmentsField, field)
+
+	term, field = parseQuery("IN:Body  coffee beans", TitleField)
+	assert.Equal(t, "coffee beans", term)
+	assert.Equal(t, BodyField, field)
+
+	term, field = parseQuery("receipts", AttachmentsField)
+	assert.Equal(t, "receipts", term)
+	assert.Equal(t, AttachmentsField, field)
+
+	term, field = parseQuery(" in:attachments ", TitleField)
+	assert.Equal(t, "", term)
+	assert.Equal(t, AttachmentsField, field)
+
+	// unknown filters are part of the term
+	term, field = parseQuery("in:space", TitleField)
+	assert.Equal(t, "in:space", term)
+	assert.Equal(t, TitleField, field)
+}
+
+func TestFilterMatches(t *testing.T) {
+	cfg := config.Default()
+	cfg.Exclude.Tags = []string{"private"}
+
+	matches := []*db.AttachmentMatch{
+		{Note: &db.Result{Title: "Receipts", Tags: "finance"}, Filename: "a.pdf"},
+		{Note: &db.Result{Title: "Diary", Tags: "private,private/2024"}, Filename: "b.jpg"},
+	}
+
+	filtered := filterMatches(cfg, matches)
+	assert.Equal(t, 1, len(filtered))
+	assert.Equal(t, "a.pdf", filtered[0].Filename)
+}
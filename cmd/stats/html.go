@@ -0,0 +1,193 @@
+package stats
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/mnadel/freddiebear/util"
+)
+
+const (
+	chartWidth  = 720
+	chartHeight = 180
+	barHeight   = 18
+)
+
+// Bar is a bar in one of the HTML report's SVG charts, positioned in pixels
+type Bar struct {
+	Label  string
+	Value  int
+	X, Y   int
+	Width  int
+	Height int
+}
+
+type htmlReport struct {
+	*Report
+	AttachmentSize string
+	ChartWidth     int
+	ChartHeight    int
+	Created        []*Bar
+	Modified       []*Bar
+	TagBars        []*Bar
+	TagsHeight     int
+	// Span is the range of periods in the activity chart
+	Span string
+}
+
+var htmlTemplate = template.Must(template.New("stats").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Notes report, {{.Generated.Format "2006-01-02"}}</title>
+<style>
+body { font: 14px -apple-system, BlinkMacSystemFont, sans-serif; color: #222; max-width: 780px; margin: 2em auto; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.2em; margin-top: 2em; border-bottom: 1px solid #ddd; }
+table { border-collapse: collapse; }
+td, th { padding: 2px 12px 2px 0; text-align: left; }
+td.n { text-align: right; }
+.totals td:first-child { color: #666; }
+.created { fill: #d9534f; }
+.modified { fill: #f0ad4e; }
+.tag { fill: #5b8def; }
+svg text { font-size: 11px; fill: #444; }
+</style>
+</head>
+<body>
+<h1>Notes report</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04"}}</p>
+
+<table class="totals">
+<tr><td>Notes</td><td>{{.Totals.Notes}} ({{.Totals.Active}} active, {{.Totals.Pinned}} pinned, {{.Totals.Archived}} archived, {{.Totals.Trashed}} trashed, {{.Totals.Encrypted}} encrypted)</td></tr>
+<tr><td>Words</td><td>{{.Totals.Words}} ({{.Totals.WordsPerNote}} per note)</td></tr>
+<tr><td>Characters</td><td>{{.Totals.Characters}} ({{.Totals.CharactersPerNote}} per note)</td></tr>
+<tr><td>Links</td><td>{{.Totals.Links}} ({{printf "%.2f" .LinkDensity}} per note, {{.Totals.LinkedNotes}} notes link to others)</td></tr>
+<tr><td>Attachments</td><td>{{.Totals.Attachments}} ({{.AttachmentSize}})</td></tr>
+</table>
+
+<h2>Activity per {{.Period}}</h2>
+<svg width="{{.ChartWidth}}" height="{{.ChartHeight}}" role="img" aria-label="Notes created and modified per {{.Period}}">
+{{range .Created}}<rect class="created" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Label}}: {{.Value}} created</title></rect>
+{{end}}{{range .Modified}}<rect class="modified" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><title>{{.Label}}: {{.Value}} modified</title></rect>
+{{end}}</svg>
+<p><svg width="10" height="10"><rect class="created" width="10" height="10"/></svg> created
+<svg width="10" height="10"><rect class="modified" width="10" height="10"/></svg> last modified
+{{with .Span}}&middot; {{.}}{{end}}</p>
+
+<h2>Tags</h2>
+<svg width="{{.ChartWidth}}" height="{{.TagsHeight}}" role="img" aria-label="Notes per tag">
+{{range .TagBars}}<text x="0" y="{{.Y}}" dy="13">{{.Label}}</text><rect class="tag" x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"/><text x="{{.X}}" y="{{.Y}}" dx="{{.Width}}" dy="13"> {{.Value}}</text>
+{{end}}</svg>
+
+<h2>Longest notes</h2>
+<table>
+<tr><th>Note</th><th>Words</th><th>Characters</th></tr>
+{{range .Longest}}<tr><td>{{.Title}}</td><td class="n">{{.Words}}</td><td class="n">{{.Characters}}</td></tr>
+{{end}}</table>
+
+<h2>Most edited</h2>
+<table>
+<tr><th>Note</th><th>Saves</th><th>Last modified</th></tr>
+{{range .MostEdited}}<tr><td>{{.Title}}</td><td class="n">{{.Saves}}</td><td>{{.Modified}}</td></tr>
+{{end}}</table>
+
+<h2>Longest untouched</h2>
+<table>
+<tr><th>Note</th><th>Last modified</th><th>Days</th></tr>
+{{range .Untouched}}<tr><td>{{.Title}}</td><td>{{.Modified}}</td><td class="n">{{.Days}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// writeHTML renders the report as a self-contained HTML page, with inline SVG charts
+func writeHTML(w io.Writer, r *Report) error {
+	view := &htmlReport{
+		Report:         r,
+		AttachmentSize: util.HumanSize(r.Totals.AttachmentBytes),
+		ChartWidth:     chartWidth,
+		ChartHeight:    chartHeight,
+		TagsHeight:     len(r.Tags) * (barHeight + 4),
+	}
+
+	if n := len(r.Activity); n > 0 {
+		view.Span = r.Activity[0].Period + " to " + r.Activity[n-1].Period
+	}
+
+	view.Created, view.Modified = activityBars(r.Activity)
+	if n := len(view.Modified); n > 0 {
+		if last := view.Modified[n-1]; last.X+last.Width > view.ChartWidth {
+			view.ChartWidth = last.X + last.Width
+		}
+	}
+	view.TagBars = tagBars(r.Tags)
+
+	return htmlTemplate.Execute(w, view)
+}
+
+// activityBars lays out a pair of bars (created, modified) per period, scaled to the chart's height
+func activityBars(activity []*Activity) ([]*Bar, []*Bar) {
+	created := make([]*Bar, 0, len(activity))
+	modified := make([]*Bar, 0, len(activity))
+
+	if len(activity) == 0 {
+		return created, modified
+	}
+
+	max := 1
+	for _, a := range activity {
+		if a.Created > max {
+			max = a.Created
+		}
+		if a.Modified > max {
+			max = a.Modified
+		}
+	}
+
+	// with many periods, the chart is wider than the page rather than the bars vanishing
+	slot := chartWidth / len(activity)
+	if slot < 2 {
+		slot = 2
+	}
+	width := slot / 2
+
+	bar := func(label string, value, x int) *Bar {
+		height := value * chartHeight / max
+		return &Bar{Label: label, Value: value, X: x, Y: chartHeight - height, Width: width, Height: height}
+	}
+
+	for i, a := range activity {
+		created = append(created, bar(a.Period, a.Created, i*slot))
+		modified = append(modified, bar(a.Period, a.Modified, i*slot+width))
+	}
+
+	return created, modified
+}
+
+// tagBars lays out a horizontal bar per tag, beside its label
+func tagBars(tags []*TagCount) []*Bar {
+	const labelWidth = 200
+
+	bars := make([]*Bar, 0, len(tags))
+
+	max := 1
+	for _, t := range tags {
+		if t.Notes > max {
+			max = t.Notes
+		}
+	}
+
+	for i, t := range tags {
+		bars = append(bars, &Bar{
+			Label:  t.Tag,
+			Value:  t.Notes,
+			X:      labelWidth,
+			Y:      i * (barHeight + 4),
+			Width:  t.Notes * (chartWidth - labelWidth - 40) / max,
+			Height: barHeight,
+		})
+	}
+
+	return bars
+}
@@ -0,0 +1,233 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+)
+
+const dateFormat = "2006-01-02 15:04:05"
+
+// Report is an overview of the whole database
+type Report struct {
+	Generated time.Time `json:"generated"`
+	Totals    Totals    `json:"totals"`
+	// LinkDensity is the average number of links per active note
+	LinkDensity float64 `json:"linkDensity"`
+	// Period is week or month
+	Period     string       `json:"period"`
+	Activity   []*Activity  `json:"activity"`
+	Tags       []*TagCount  `json:"tags"`
+	Longest    []*NoteEntry `json:"longest"`
+	MostEdited []*NoteEntry `json:"mostEdited"`
+	Untouched  []*NoteEntry `json:"untouched"`
+}
+
+// Totals counts notes by state, along with the size of the active notes and attachments
+type Totals struct {
+	Notes     int `json:"notes"`
+	Active    int `json:"active"`
+	Pinned    int `json:"pinned"`
+	Archived  int `json:"archived"`
+	Trashed   int `json:"trashed"`
+	Encrypted int `json:"encrypted"`
+	// Words and Characters are totals over active notes
+	Words      int `json:"words"`
+	Characters int `json:"characters"`
+	// WordsPerNote and CharactersPerNote are averages over active notes
+	WordsPerNote      int   `json:"wordsPerNote"`
+	CharactersPerNote int   `json:"charactersPerNote"`
+	Links             int   `json:"links"`
+	LinkedNotes       int   `json:"linkedNotes"`
+	Attachments       int   `json:"attachments"`
+	AttachmentBytes   int64 `json:"attachmentBytes"`
+}
+
+// Activity is the number of notes created, and last modified, in a week or month
+type Activity struct {
+	Period   string `json:"period"`
+	Created  int    `json:"created"`
+	Modified int    `json:"modified"`
+}
+
+// TagCount is the number of active notes with a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Notes int    `json:"notes"`
+}
+
+// NoteEntry is a note in one of the report's top lists
+type NoteEntry struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Modified   string `json:"modified"`
+	Words      int    `json:"words"`
+	Characters int    `json:"characters"`
+	Saves      int    `json:"saves"`
+	// Days is the number of days since the note was last modified
+	Days int `json:"days"`
+}
+
+// buildReport summarizes the notes and attachments. Lists are limited to the top notes or tags,
+// and activity is grouped by week or month.
+func buildReport(notes []*db.NoteStat, attachments []*db.AttachmentInfo, now time.Time, period string, top int) (*Report, error) {
+	if period != "week" && period != "month" {
+		return nil, fmt.Errorf("unknown period: %s", period)
+	}
+
+	report := &Report{
+		Generated: now,
+		Period:    period,
+		Activity:  make([]*Activity, 0),
+		Tags:      make([]*TagCount, 0),
+	}
+	t := &report.Totals
+
+	activity := make(map[string]*Activity)
+	tags := make(map[string]int)
+	active := make([]*NoteEntry, 0, len(notes))
+
+	bump := func(date string, f func(*Activity)) {
+		when, err := time.ParseInLocation(dateFormat, date, now.Location())
+		if err != nil {
+			return
+		}
+		key := periodKey(when, period)
+		a, ok := activity[key]
+		if !ok {
+			a = &Activity{Period: key}
+			activity[key] = a
+		}
+		f(a)
+	}
+
+	for _, n := range notes {
+		t.Notes++
+
+		switch {
+		case n.Trashed:
+			t.Trashed++
+		case n.Archived:
+			t.Archived++
+		default:
+			t.Active++
+		}
+
+		if n.Pinned && !n.Trashed {
+			t.Pinned++
+		}
+		if n.Encrypted {
+			t.Encrypted++
+		}
+
+		bump(n.Created, func(a *Activity) { a.Created++ })
+		bump(n.Modified, func(a *Activity) { a.Modified++ })
+
+		if n.Trashed || n.Archived {
+			continue
+		}
+
+		entry := &NoteEntry{
+			ID:         n.ID,
+			Title:      n.Title,
+			Modified:   n.Modified,
+			Words:      len(strings.Fields(n.Text)),
+			Characters: utf8.RuneCountInString(n.Text),
+			Saves:      n.Saves,
+		}
+
+		if modified, err := time.ParseInLocation(dateFormat, n.Modified, now.Location()); err == nil {
+			entry.Days = daysBetween(modified, now)
+		}
+
+		active = append(active, entry)
+
+		t.Words += entry.Words
+		t.Characters += entry.Characters
+		t.Links += n.Links
+		if n.Links > 0 {
+			t.LinkedNotes++
+		}
+
+		for _, tag := range n.Tags {
+			tags[tag]++
+		}
+	}
+
+	if t.Active > 0 {
+		t.WordsPerNote = t.Words / t.Active
+		t.CharactersPerNote = t.Characters / t.Active
+		report.LinkDensity = float64(t.Links) / float64(t.Active)
+	}
+
+	for _, a := range attachments {
+		if a.NoteID == "" || a.Trashed {
+			continue
+		}
+		t.Attachments++
+		t.AttachmentBytes += a.Size
+	}
+
+	for _, a := range activity {
+		report.Activity = append(report.Activity, a)
+	}
+	sort.Slice(report.Activity, func(i, j int) bool {
+		return report.Activity[i].Period < report.Activity[j].Period
+	})
+
+	for tag, count := range tags {
+		report.Tags = append(report.Tags, &TagCount{Tag: tag, Notes: count})
+	}
+	sort.Slice(report.Tags, func(i, j int) bool {
+		if report.Tags[i].Notes != report.Tags[j].Notes {
+			return report.Tags[i].Notes > report.Tags[j].Notes
+		}
+		return report.Tags[i].Tag < report.Tags[j].Tag
+	})
+	report.Tags = util.Limit(report.Tags, top)
+
+	report.Longest = topNotes(active, top, func(a, b *NoteEntry) bool { return a.Words > b.Words })
+	report.MostEdited = topNotes(active, top, func(a, b *NoteEntry) bool { return a.Saves > b.Saves })
+	report.Untouched = topNotes(active, top, func(a, b *NoteEntry) bool { return a.Modified < b.Modified })
+
+	return report, nil
+}
+
+// periodKey names the ISO week (2024-W05) or month (2024-02) of the time
+func periodKey(t time.Time, period string) string {
+	if period == "week" {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return t.Format("2006-01")
+}
+
+func topNotes(notes []*NoteEntry, top int, less func(a, b *NoteEntry) bool) []*NoteEntry {
+	sorted := make([]*NoteEntry, len(notes))
+	copy(sorted, notes)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if less(sorted[i], sorted[j]) {
+			return true
+		} else if less(sorted[j], sorted[i]) {
+			return false
+		}
+		return sorted[i].Title < sorted[j].Title
+	})
+
+	return util.Limit(sorted, top)
+}
+
+// daysBetween returns the number of calendar days from one time to another, ignoring their times of
+// day, so that a daylight saving change in between doesn't lose a day
+func daysBetween(from, to time.Time) int {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(end.Sub(start).Hours() / 24)
+}
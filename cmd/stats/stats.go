@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optFormat string
+	optPeriod string
+	optTop    int
+	optOutput string
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Report on your notes",
+		Long: `Report on the whole database: notes created and modified over time, words and characters, the longest,
+most-edited and longest-untouched notes, tag distribution, link density, note states, and attachment volume`,
+		Args: cobra.NoArgs,
+		RunE: runner,
+	}
+
+	cmd.Flags().StringVar(&optFormat, "format", "text", "output format (text, json, html)")
+	cmd.Flags().StringVar(&optPeriod, "period", "month", "group activity by week or month")
+	cmd.Flags().IntVar(&optTop, "top", 10, "number of notes and tags in each list")
+	cmd.Flags().StringVarP(&optOutput, "output", "o", "", "write the report to this file (default: stdout)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	notes, err := bearDB.NoteStats()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	attachments, err := bearDB.AttachmentInventory()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	report, err := buildReport(notes, attachments, time.Now(), optPeriod, optTop)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if optOutput != "" {
+		f, err := os.Create(optOutput)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch optFormat {
+	case "text":
+		return errors.WithStack(writeText(w, report))
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return errors.WithStack(encoder.Encode(report))
+	case "html":
+		return errors.WithStack(writeHTML(w, report))
+	}
+
+	return fmt.Errorf("unknown format: %s", optFormat)
+}
+
+func writeText(w io.Writer, r *Report) error {
+	t := r.Totals
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Notes\t%d (%d active, %d pinned, %d archived, %d trashed, %d encrypted)\n",
+		t.Notes, t.Active, t.Pinned, t.Archived, t.Trashed, t.Encrypted)
+	fmt.Fprintf(tw, "Words\t%d (%d per note)\n", t.Words, t.WordsPerNote)
+	fmt.Fprintf(tw, "Characters\t%d (%d per note)\n", t.Characters, t.CharactersPerNote)
+	fmt.Fprintf(tw, "Links\t%d (%.2f per note, %d notes link to others)\n", t.Links, r.LinkDensity, t.LinkedNotes)
+	fmt.Fprintf(tw, "Attachments\t%d (%s)\n", t.Attachments, util.HumanSize(t.AttachmentBytes))
+
+	fmt.Fprintf(tw, "\nACTIVITY (%s)\tCREATED\tMODIFIED\n", r.Period)
+	for _, a := range r.Activity {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", a.Period, a.Created, a.Modified)
+	}
+
+	fmt.Fprintln(tw, "\nTAG\tNOTES")
+	for _, tag := range r.Tags {
+		fmt.Fprintf(tw, "%s\t%d\n", tag.Tag, tag.Notes)
+	}
+
+	fmt.Fprintln(tw, "\nLONGEST\tWORDS")
+	for _, n := range r.Longest {
+		fmt.Fprintf(tw, "%s\t%d\n", n.Title, n.Words)
+	}
+
+	fmt.Fprintln(tw, "\nMOST EDITED\tSAVES")
+	for _, n := range r.MostEdited {
+		fmt.Fprintf(tw, "%s\t%d\n", n.Title, n.Saves)
+	}
+
+	fmt.Fprintln(tw, "\nLONGEST UNTOUCHED\tLAST MODIFIED")
+	for _, n := range r.Untouched {
+		fmt.Fprintf(tw, "%s\t%s (%d days)\n", n.Title, n.Modified, n.Days)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,151 @@
+package stats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func testNotes() []*db.NoteStat {
+	return []*db.NoteStat{
+		{ID: "a", Title: "Alpha", Created: "2024-01-02 10:00:00", Modified: "2024-03-14 12:00:00", Text: "one two three four", Tags: []string{"work", "work/meetings"}, Pinned: true, Saves: 40, Links: 2},
+		{ID: "b", Title: "Beta", Created: "2024-01-20 10:00:00", Modified: "2024-01-20 12:00:00", Text: "héllo world", Tags: []string{"work"}, Saves: 3},
+		{ID: "c", Title: "Gamma", Created: "2024-02-01 10:00:00", Modified: "2024-02-10 12:00:00", Text: "just one more note here today", Saves: 12, Links: 1},
+		{ID: "d", Title: "Archived", Created: "2023-12-01 10:00:00", Modified: "2023-12-01 10:00:00", Text: "old words", Archived: true, Pinned: true},
+		{ID: "e", Title: "Trashed", Created: "2024-02-05 10:00:00", Modified: "2024-02-06 10:00:00", Text: "gone", Trashed: true, Pinned: true, Tags: []string{"work"}},
+		{ID: "f", Title: "Secret", Created: "2024-03-01 10:00:00", Modified: "2024-03-01 10:00:00", Encrypted: true},
+	}
+}
+
+func titles(entries []*NoteEntry) []string {
+	result := make([]string, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.Title)
+	}
+	return result
+}
+
+func TestBuildReport(t *testing.T) {
+	attachments := []*db.AttachmentInfo{
+		{NoteID: "a", Size: 1000},
+		{NoteID: "c", Size: 500},
+		{NoteID: "e", Size: 9999, Trashed: true},
+		{Size: 9999},
+	}
+
+	report, err := buildReport(testNotes(), attachments, now, "month", 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, Totals{
+		Notes:             6,
+		Active:            4,
+		Pinned:            2,
+		Archived:          1,
+		Trashed:           1,
+		Encrypted:         1,
+		Words:             12,
+		Characters:        18 + 11 + 29,
+		WordsPerNote:      3,
+		CharactersPerNote: 14,
+		Links:             3,
+		LinkedNotes:       2,
+		Attachments:       2,
+		AttachmentBytes:   1500,
+	}, report.Totals)
+	assert.Equal(t, 0.75, report.LinkDensity)
+
+	assert.Equal(t, []*Activity{
+		{Period: "2023-12", Created: 1, Modified: 1},
+		{Period: "2024-01", Created: 2, Modified: 1},
+		{Period: "2024-02", Created: 2, Modified: 2},
+		{Period: "2024-03", Created: 1, Modified: 2},
+	}, report.Activity)
+
+	assert.Equal(t, []*TagCount{{Tag: "work", Notes: 2}, {Tag: "work/meetings", Notes: 1}}, report.Tags)
+	assert.Equal(t, []string{"Gamma", "Alpha"}, titles(report.Longest))
+	assert.Equal(t, []string{"Alpha", "Gamma"}, titles(report.MostEdited))
+	assert.Equal(t, []string{"Beta", "Gamma"}, titles(report.Untouched))
+	assert.Equal(t, 55, report.Untouched[0].Days)
+}
+
+func TestBuildReportByWeek(t *testing.T) {
+	report, err := buildReport(testNotes()[:2], nil, now, "week", 10)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*Activity{
+		{Period: "2024-W01", Created: 1},
+		{Period: "2024-W03", Created: 1, Modified: 1},
+		{Period: "2024-W11", Modified: 1},
+	}, report.Activity)
+
+	_, err = buildReport(nil, nil, now, "fortnight", 10)
+	assert.Error(t, err)
+}
+
+func TestPeriodKey(t *testing.T) {
+	// the ISO week of a date can belong to the previous or next year
+	assert.Equal(t, "2020-W53", periodKey(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), "week"))
+	assert.Equal(t, "2021-01", periodKey(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), "month"))
+}
+
+func TestWriteHTML(t *testing.T) {
+	notes := testNotes()
+	notes[0].Title = "<script>alert(1)</script>"
+
+	report, err := buildReport(notes, nil, now, "month", 10)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeHTML(&buf, report))
+
+	html := buf.String()
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>"))
+	assert.Contains(t, html, `<rect class="created"`)
+	assert.Contains(t, html, `<rect class="tag"`)
+	assert.Contains(t, html, "2023-12 to 2024-03")
+	assert.Contains(t, html, "&lt;script&gt;")
+	assert.NotContains(t, html, "<script>")
+}
+
+func TestActivityBars(t *testing.T) {
+	created, modified := activityBars([]*Activity{
+		{Period: "2024-01", Created: 4, Modified: 2},
+		{Period: "2024-02", Created: 1, Modified: 0},
+	})
+
+	assert.Equal(t, &Bar{Label: "2024-01", Value: 4, X: 0, Y: 0, Width: 180, Height: chartHeight}, created[0])
+	assert.Equal(t, &Bar{Label: "2024-01", Value: 2, X: 180, Y: 90, Width: 180, Height: 90}, modified[0])
+	assert.Equal(t, 360, created[1].X)
+	assert.Equal(t, 0, modified[1].Height)
+}
+
+func TestWriteText(t *testing.T) {
+	report, err := buildReport(testNotes(), nil, now, "month", 10)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeText(&buf, report))
+
+	assert.Contains(t, buf.String(), "6 (4 active, 2 pinned, 1 archived, 1 trashed, 1 encrypted)")
+	assert.Contains(t, buf.String(), "0.75 per note")
+}
+
+func TestDaysBetweenAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+
+	// clocks spring forward on 2024-03-10, so these are only 47 hours apart
+	from := time.Date(2024, 3, 9, 9, 0, 0, 0, loc)
+	to := time.Date(2024, 3, 11, 9, 0, 0, 0, loc)
+
+	assert.Equal(t, 2, daysBetween(from, to))
+	assert.Equal(t, 1, daysBetween(from, to.Add(-10*time.Hour)))
+}
@@ -0,0 +1,244 @@
+package tags
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+)
+
+// TagStat summarizes how a single tag is used
+type TagStat struct {
+	Tag      string `json:"tag"`
+	Notes    int    `json:"notes"`
+	Total    int    `json:"total"`
+	LastUsed string `json:"lastUsed"`
+}
+
+// TagEdge connects two tags that appear on the same notes
+type TagEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+}
+
+// GraphNode is a tag in the co-occurrence graph, with the number of notes it's a leaf tag of
+type GraphNode struct {
+	Tag   string `json:"tag"`
+	Notes int    `json:"notes"`
+}
+
+// buildNodes returns a node for every leaf tag, whether or not it shares a note with another tag
+func buildNodes(notes []*db.TaggedNote) []*GraphNode {
+	counts := make(map[string]int)
+
+	for _, note := range notes {
+		for _, tag := range leafTags(note.Tags) {
+			counts[tag]++
+		}
+	}
+
+	nodes := make([]*GraphNode, 0, len(counts))
+	for tag, count := range counts {
+		nodes = append(nodes, &GraphNode{tag, count})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Tag < nodes[j].Tag
+	})
+
+	return nodes
+}
+
+// buildStats computes per-tag usage. Notes counts the notes where the tag is a leaf,
+// Total also includes notes tagged with any of the tag's nested children.
+func buildStats(notes []*db.TaggedNote) []*TagStat {
+	stats := make(map[string]*TagStat)
+
+	stat := func(tag string) *TagStat {
+		s, ok := stats[tag]
+		if !ok {
+			s = &TagStat{Tag: tag}
+			stats[tag] = s
+		}
+		return s
+	}
+
+	for _, note := range notes {
+		for _, tag := range expandTags(note.Tags) {
+			s := stat(tag)
+			s.Total++
+			if note.ModificationDate > s.LastUsed {
+				s.LastUsed = note.ModificationDate
+			}
+		}
+
+		for _, tag := range leafTags(note.Tags) {
+			stat(tag).Notes++
+		}
+	}
+
+	results := make([]*TagStat, 0, len(stats))
+	for _, s := range stats {
+		results = append(results, s)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Tag < results[j].Tag
+	})
+
+	return results
+}
+
+// singleNoteTags returns the tags that are used by exactly one note
+func singleNoteTags(stats []*TagStat) []string {
+	singles := make([]string, 0)
+
+	for _, s := range stats {
+		if s.Total == 1 {
+			singles = append(singles, s.Tag)
+		}
+	}
+
+	return singles
+}
+
+// buildCooccurrence returns an edge for every pair of leaf tags that share a note,
+// weighted by the number of notes they share
+func buildCooccurrence(notes []*db.TaggedNote) []*TagEdge {
+	weights := make(map[[2]string]int)
+
+	for _, note := range notes {
+		tags := leafTags(note.Tags)
+		sort.Strings(tags)
+
+		for i := 0; i < len(tags); i++ {
+			for j := i + 1; j < len(tags); j++ {
+				weights[[2]string{tags[i], tags[j]}]++
+			}
+		}
+	}
+
+	edges := make([]*TagEdge, 0, len(weights))
+	for pair, weight := range weights {
+		edges = append(edges, &TagEdge{pair[0], pair[1], weight})
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Weight != edges[j].Weight {
+			return edges[i].Weight > edges[j].Weight
+		}
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+
+	return edges
+}
+
+// nearDuplicates returns pairs of tags whose names likely refer to the same thing,
+// e.g. meeting and meetings, or todo and to-do
+func nearDuplicates(tags []string) [][2]string {
+	sorted := util.UniqueSet(tags)
+	sort.Strings(sorted)
+
+	normalized := make([]string, len(sorted))
+	for i, t := range sorted {
+		normalized[i] = normalizeTag(t)
+	}
+
+	dupes := make([][2]string, 0)
+
+	for i := 0; i < len(sorted); i++ {
+		for j := i + 1; j < len(sorted); j++ {
+			a, b := normalized[i], normalized[j]
+			if a == b || (len(a) >= 5 && len(b) >= 5 && editDistance(a, b) == 1) {
+				dupes = append(dupes, [2]string{sorted[i], sorted[j]})
+			}
+		}
+	}
+
+	return dupes
+}
+
+// expandTags returns the tags along with all of their parent tags ([a/b/c] -> [a a/b a/b/c])
+func expandTags(tags []string) []string {
+	expanded := make([]string, 0, len(tags))
+
+	for _, tag := range tags {
+		parts := strings.Split(tag, "/")
+		for i := range parts {
+			expanded = append(expanded, strings.Join(parts[:i+1], "/"))
+		}
+	}
+
+	return util.UniqueSet(expanded)
+}
+
+// leafTags returns the non-intermediate tags, without mutating the input
+func leafTags(tags []string) []string {
+	copied := make([]string, len(tags))
+	copy(copied, tags)
+
+	return util.RemoveIntermediatePrefixes(copied, "/")
+}
+
+// normalizeTag lowercases the tag, drops punctuation, and singularizes each path segment
+func normalizeTag(tag string) string {
+	segments := strings.Split(strings.ToLower(tag), "/")
+
+	for i, seg := range segments {
+		seg = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, seg)
+
+		segments[i] = singular(seg)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"):
+		return strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word) > 3:
+		return strings.TrimSuffix(word, "s")
+	default:
+		return word
+	}
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
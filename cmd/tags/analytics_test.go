@@ -0,0 +1,83 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testNotes() []*db.TaggedNote {
+	return []*db.TaggedNote{
+		{ID: "1", ModificationDate: "2024-01-01 10:00:00", Tags: []string{"work", "work/meeting", "people"}},
+		{ID: "2", ModificationDate: "2024-02-01 10:00:00", Tags: []string{"work", "work/meetings", "people"}},
+		{ID: "3", ModificationDate: "2024-03-01 10:00:00", Tags: []string{"work", "work/meeting"}},
+		{ID: "4", ModificationDate: "2024-04-01 10:00:00", Tags: []string{}},
+	}
+}
+
+func TestBuildStats(t *testing.T) {
+	stats := buildStats(testNotes())
+	byTag := make(map[string]*TagStat)
+	for _, s := range stats {
+		byTag[s.Tag] = s
+	}
+
+	assert.Equal(t, 4, len(stats))
+
+	assert.Equal(t, 0, byTag["work"].Notes)
+	assert.Equal(t, 3, byTag["work"].Total)
+	assert.Equal(t, "2024-03-01 10:00:00", byTag["work"].LastUsed)
+
+	assert.Equal(t, 2, byTag["work/meeting"].Notes)
+	assert.Equal(t, 1, byTag["work/meetings"].Total)
+
+	assert.Equal(t, []string{"work/meetings"}, singleNoteTags(stats))
+}
+
+func TestBuildStatsIncludesMissingParents(t *testing.T) {
+	notes := []*db.TaggedNote{{ID: "1", Tags: []string{"a/b/c"}}}
+	stats := buildStats(notes)
+
+	assert.Equal(t, 3, len(stats))
+	assert.Equal(t, "a", stats[0].Tag)
+	assert.Equal(t, 1, stats[0].Total)
+	assert.Equal(t, 0, stats[0].Notes)
+}
+
+func TestBuildCooccurrence(t *testing.T) {
+	edges := buildCooccurrence(testNotes())
+
+	assert.Equal(t, 2, len(edges), edges)
+	assert.Equal(t, &TagEdge{"people", "work/meeting", 1}, edges[0])
+	assert.Equal(t, &TagEdge{"people", "work/meetings", 1}, edges[1])
+}
+
+func TestNearDuplicates(t *testing.T) {
+	dupes := nearDuplicates([]string{"meeting", "meetings", "to-do", "todo", "books", "work", "works/libraries", "works/library", "project", "projekt"})
+
+	assert.Contains(t, dupes, [2]string{"meeting", "meetings"})
+	assert.Contains(t, dupes, [2]string{"to-do", "todo"})
+	assert.Contains(t, dupes, [2]string{"works/libraries", "works/library"})
+	assert.Contains(t, dupes, [2]string{"project", "projekt"})
+	assert.NotContains(t, dupes, [2]string{"books", "work"})
+	assert.Equal(t, 4, len(dupes), dupes)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("abc", "abc"))
+	assert.Equal(t, 1, editDistance("abc", "abd"))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 2, editDistance("projcet", "project"))
+}
+
+func TestBuildNodes(t *testing.T) {
+	notes := append(testNotes(), &db.TaggedNote{ID: "5", Tags: []string{"solo"}})
+
+	assert.Equal(t, []*GraphNode{
+		{"people", 2},
+		{"solo", 1},
+		{"work/meeting", 2},
+		{"work/meetings", 1},
+	}, buildNodes(notes))
+}
@@ -0,0 +1,122 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func newBrowseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "browse [path]",
+		Short: "Browse the tag hierarchy",
+		Long:  "Generate the child tags and notes of a tag path in Alfred Workflow's JSON schema format. Selecting a child tag drills down into it.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  browseRunner,
+	}
+
+	return cmd
+}
+
+func browseRunner(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	notes, err := bearDB.QueryTaggedNotes()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, note := range notes {
+		note.Tags = cfg.FilterTags(note.Tags)
+	}
+
+	tags, err := bearDB.QueryTagTitles()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	query := ""
+	if len(args) == 1 {
+		query = args[0]
+	}
+
+	output, err := alfred.AlfredJSON(browseItems(buildTagTree(cfg.FilterTags(tags), notes), query))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Print(output)
+
+	return nil
+}
+
+// browseItems returns the Alfred items for a (possibly partially typed) tag path: `work/pro` lists
+// the children of `work` that start with `pro`, and `work/` lists all children of `work` along with
+// the notes tagged exactly `work`
+func browseItems(root *TagNode, query string) []*alfred.Item {
+	query = strings.TrimPrefix(query, "#")
+
+	parentPath, filter := "", query
+	if i := strings.LastIndex(query, "/"); i >= 0 {
+		parentPath, filter = query[:i], query[i+1:]
+	}
+
+	items := make([]*alfred.Item, 0)
+
+	parent := root.Find(parentPath)
+	if parent == nil {
+		return append(items, &alfred.Item{Title: "No such tag: " + parentPath})
+	}
+
+	for _, child := range parent.SortedChildren() {
+		if !strings.HasPrefix(strings.ToLower(child.Name), strings.ToLower(filter)) {
+			continue
+		}
+
+		subtitle := util.Pluralize(child.Total, "note")
+		if len(child.Children) > 0 {
+			subtitle = fmt.Sprintf("%s, %s", util.Pluralize(len(child.Children), "subtag"), util.Pluralize(child.Total, "note"))
+		}
+
+		items = append(items, &alfred.Item{
+			UID:          "tag:" + child.Path,
+			Title:        child.Path + "/",
+			Subtitle:     subtitle,
+			Autocomplete: child.Path + "/",
+			Valid:        false,
+		})
+	}
+
+	if parent != root && filter == "" {
+		for _, note := range parent.Notes {
+			items = append(items, &alfred.Item{
+				UID:      note.ID,
+				Title:    util.ToTitleCase(note.Title),
+				Subtitle: "#" + parent.Path,
+				Arg:      note.ID,
+				Valid:    true,
+			})
+		}
+	}
+
+	if len(items) == 0 {
+		items = append(items, &alfred.Item{Title: "No matching tags"})
+	}
+
+	return items
+}
@@ -0,0 +1,87 @@
+package tags
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optGraphFormat    string
+	optGraphMinWeight int
+)
+
+func newGraphCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Visualize tag co-occurrence",
+		Long:  "Generate a DOT or JSON graph of tags, with edges weighted by the number of notes that share them",
+		Args:  cobra.NoArgs,
+		RunE:  graphRunner,
+	}
+
+	cmd.Flags().StringVar(&optGraphFormat, "format", "dot", "output format (dot, json)")
+	cmd.Flags().IntVar(&optGraphMinWeight, "min-weight", 1, "only include edges shared by at least this many notes")
+
+	return cmd
+}
+
+func graphRunner(cmd *cobra.Command, args []string) error {
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	notes, err := bearDB.QueryTaggedNotes()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	edges := make([]*TagEdge, 0)
+	for _, edge := range buildCooccurrence(notes) {
+		if edge.Weight >= optGraphMinWeight {
+			edges = append(edges, edge)
+		}
+	}
+
+	nodes := buildNodes(notes)
+
+	switch optGraphFormat {
+	case "dot":
+		printDOT(nodes, edges)
+	case "json":
+		return printGraphJSON(nodes, edges)
+	default:
+		return fmt.Errorf("unknown format: %s", optGraphFormat)
+	}
+
+	return nil
+}
+
+func printDOT(nodes []*GraphNode, edges []*TagEdge) {
+	fmt.Println("graph Tags {")
+
+	for _, node := range nodes {
+		fmt.Printf("	%q [label=%q];\n", node.Tag, fmt.Sprintf("%s (%d)", node.Tag, node.Notes))
+	}
+
+	for _, edge := range edges {
+		fmt.Printf("	%q -- %q [weight=%d, label=\"%d\"];\n", edge.Source, edge.Target, edge.Weight, edge.Weight)
+	}
+
+	fmt.Println("}")
+}
+
+func printGraphJSON(nodes []*GraphNode, edges []*TagEdge) error {
+	graph := struct {
+		Nodes []*GraphNode `json:"nodes"`
+		Edges []*TagEdge   `json:"edges"`
+	}{nodes, edges}
+
+	return errors.WithStack(json.NewEncoder(os.Stdout).Encode(graph))
+}
@@ -0,0 +1,97 @@
+package tags
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	optStatsFormat string
+)
+
+// Report is the full set of tag analytics
+type Report struct {
+	Tags       []*TagStat  `json:"tags"`
+	SingleNote []string    `json:"singleNote"`
+	Duplicates [][2]string `json:"duplicates"`
+}
+
+func newStatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Report on tag usage",
+		Long:  "Report note counts and last-used dates per tag, tags with a single note, and near-duplicate tag names",
+		Args:  cobra.NoArgs,
+		RunE:  statsRunner,
+	}
+
+	cmd.Flags().StringVar(&optStatsFormat, "format", "text", "output format (text, json)")
+
+	return cmd
+}
+
+func statsRunner(cmd *cobra.Command, args []string) error {
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	notes, err := bearDB.QueryTaggedNotes()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	stats := buildStats(notes)
+
+	names := make([]string, len(stats))
+	for i, s := range stats {
+		names[i] = s.Tag
+	}
+
+	report := &Report{
+		Tags:       stats,
+		SingleNote: singleNoteTags(stats),
+		Duplicates: nearDuplicates(names),
+	}
+
+	switch optStatsFormat {
+	case "text":
+		return printReport(report)
+	case "json":
+		return errors.WithStack(json.NewEncoder(os.Stdout).Encode(report))
+	default:
+		return fmt.Errorf("unknown format: %s", optStatsFormat)
+	}
+}
+
+func printReport(report *Report) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "TAG\tNOTES\tTOTAL\tLAST USED")
+	for _, s := range report.Tags {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", s.Tag, s.Notes, s.Total, s.LastUsed)
+	}
+
+	if err := w.Flush(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Printf("\nTags with a single note (%d):\n", len(report.SingleNote))
+	for _, tag := range report.SingleNote {
+		fmt.Printf("  %s\n", tag)
+	}
+
+	fmt.Printf("\nPossible duplicates (%d):\n", len(report.Duplicates))
+	for _, pair := range report.Duplicates {
+		fmt.Printf("  %s ~ %s\n", pair[0], pair[1])
+	}
+
+	return nil
+}
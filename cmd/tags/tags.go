@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mnadel/freddiebear/config"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -17,10 +18,19 @@ func New() *cobra.Command {
 		RunE:  runner,
 	}
 
+	cmd.AddCommand(newGraphCmd())
+	cmd.AddCommand(newStatsCmd())
+	cmd.AddCommand(newBrowseCmd())
+
 	return cmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
@@ -34,10 +44,8 @@ func runner(cmd *cobra.Command, args []string) error {
 
 	items := make([]string, 0)
 
-	for _, t := range allTags {
-		if !strings.Contains(t, "captainslog") {
-			items = append(items, fmt.Sprintf(`{"title":"%s","arg":"%s"}`, t, t))
-		}
+	for _, t := range cfg.FilterTags(allTags) {
+		items = append(items, fmt.Sprintf(`{"title":"%s","arg":"%s"}`, t, t))
 	}
 
 	fmt.Printf(`{"items":[%s]}`, strings.Join(items, ","))
@@ -0,0 +1,143 @@
+package tags
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mnadel/freddiebear/db"
+)
+
+// TagNode is a tag within the tag hierarchy
+type TagNode struct {
+	Name     string
+	Path     string
+	Children map[string]*TagNode
+	// Notes are tagged exactly at this level: they're joined to this tag, but to none of the tags below it
+	Notes []*db.TaggedNote
+	// Total is the number of notes tagged at this level or any level below it
+	Total int
+
+	parent *TagNode
+}
+
+// buildTagTree builds the tag hierarchy from every tag (including those without any notes), then
+// attaches each note to the tags it's joined to, returning the (unnamed) root
+func buildTagTree(tags []string, notes []*db.TaggedNote) *TagNode {
+	root := newTagNode("", "")
+
+	for _, tag := range tags {
+		root.insert(tag)
+	}
+
+	for _, note := range notes {
+		joined := make([]*TagNode, 0, len(note.Tags))
+		for _, tag := range note.Tags {
+			joined = append(joined, root.insert(tag))
+		}
+
+		counted := make(map[*TagNode]bool)
+
+		for _, node := range joined {
+			for n := node; n != root; n = n.parent {
+				if !counted[n] {
+					counted[n] = true
+					n.Total++
+				}
+			}
+		}
+
+		for _, node := range joined {
+			if !node.isAncestorOfAny(joined) && !containsNote(node.Notes, note) {
+				node.Notes = append(node.Notes, note)
+			}
+		}
+	}
+
+	return root
+}
+
+// Find returns the node at the given path, or nil if there's no such tag
+func (n *TagNode) Find(path string) *TagNode {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return n
+	}
+
+	node := n
+	for _, name := range strings.Split(path, "/") {
+		child, ok := node.Children[name]
+		if !ok {
+			return nil
+		}
+		node = child
+	}
+
+	return node
+}
+
+// SortedChildren returns the node's children, ordered by name
+func (n *TagNode) SortedChildren() []*TagNode {
+	children := make([]*TagNode, 0, len(n.Children))
+	for _, child := range n.Children {
+		children = append(children, child)
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name < children[j].Name
+	})
+
+	return children
+}
+
+// isAncestorOfAny returns true if any of the nodes are beneath this one
+func (n *TagNode) isAncestorOfAny(nodes []*TagNode) bool {
+	for _, node := range nodes {
+		for p := node.parent; p != nil; p = p.parent {
+			if p == n {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsNote(notes []*db.TaggedNote, note *db.TaggedNote) bool {
+	for _, n := range notes {
+		if n == note {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *TagNode) insert(path string) *TagNode {
+	node := n
+
+	for _, name := range strings.Split(path, "/") {
+		child, ok := node.Children[name]
+		if !ok {
+			childPath := name
+			if node.Path != "" {
+				childPath = node.Path + "/" + name
+			}
+
+			child = newTagNode(name, childPath)
+			child.parent = node
+			node.Children[name] = child
+		}
+		node = child
+	}
+
+	return node
+}
+
+func newTagNode(name, path string) *TagNode {
+	return &TagNode{
+		Name:     name,
+		Path:     path,
+		Children: make(map[string]*TagNode),
+		Notes:    make([]*db.TaggedNote, 0),
+	}
+}
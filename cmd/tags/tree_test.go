@@ -0,0 +1,80 @@
+package tags
+
+import (
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTagTree(t *testing.T) {
+	root := buildTagTree([]string{"work", "work/meeting", "ideas", "ideas/someday"}, testNotes())
+
+	assert.Equal(t, 3, len(root.Children))
+
+	// tags without notes are still in the tree
+	someday := root.Find("ideas/someday")
+	assert.NotNil(t, someday)
+	assert.Equal(t, 0, someday.Total)
+
+	work := root.Find("work")
+	assert.NotNil(t, work)
+	assert.Equal(t, 3, work.Total)
+	assert.Equal(t, 0, len(work.Notes))
+	assert.Equal(t, 2, len(work.Children))
+
+	meeting := root.Find("work/meeting/")
+	assert.NotNil(t, meeting)
+	assert.Equal(t, "work/meeting", meeting.Path)
+	assert.Equal(t, 2, len(meeting.Notes))
+
+	assert.Nil(t, root.Find("work/nope"))
+	assert.Equal(t, root, root.Find(""))
+}
+
+func TestBrowseItems(t *testing.T) {
+	notes := append(testNotes(), &db.TaggedNote{ID: "5", Title: "status update", Tags: []string{"work"}})
+	root := buildTagTree(nil, notes)
+
+	items := browseItems(root, "")
+	assert.Equal(t, 2, len(items))
+	assert.Equal(t, "people/", items[0].Autocomplete)
+	assert.Equal(t, "work/", items[1].Autocomplete)
+	assert.Equal(t, "2 subtags, 4 notes", items[1].Subtitle)
+	assert.False(t, items[1].Valid)
+
+	items = browseItems(root, "work/")
+	assert.Equal(t, 3, len(items))
+	assert.Equal(t, "work/meeting/", items[0].Title)
+	assert.Equal(t, "2 notes", items[0].Subtitle)
+	assert.Equal(t, "work/meetings/", items[1].Title)
+	assert.Equal(t, "Status Update", items[2].Title)
+	assert.Equal(t, "5", items[2].Arg)
+	assert.True(t, items[2].Valid)
+
+	items = browseItems(root, "#work/meetings")
+	assert.Equal(t, 1, len(items))
+	assert.Equal(t, "work/meetings/", items[0].Autocomplete)
+
+	items = browseItems(root, "nope/")
+	assert.Equal(t, 1, len(items))
+	assert.False(t, items[0].Valid)
+}
+
+func TestBuildTagTreeAttachesNotesThroughJoinedTags(t *testing.T) {
+	notes := []*db.TaggedNote{
+		// Bear joins a note to the parents of its nested tags
+		{ID: "1", Tags: []string{"a", "a/b", "a/b/c"}},
+		// but a note tagged only with a nested tag, whose parents are missing, is still counted by them
+		{ID: "2", Tags: []string{"a/b"}},
+		{ID: "3", Tags: []string{"a"}},
+	}
+
+	root := buildTagTree([]string{"a", "a/b", "a/b/c"}, notes)
+
+	assert.Equal(t, 3, root.Find("a").Total)
+	assert.Equal(t, 2, root.Find("a/b").Total)
+	assert.Equal(t, []*db.TaggedNote{notes[2]}, root.Find("a").Notes)
+	assert.Equal(t, []*db.TaggedNote{notes[1]}, root.Find("a/b").Notes)
+	assert.Equal(t, []*db.TaggedNote{notes[0]}, root.Find("a/b/c").Notes)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/mnadel/freddiebear/db/exporter"
 	"github.com/mnadel/freddiebear/util"
@@ -12,7 +14,9 @@ import (
 )
 
 var (
-	filenameAsArg bool
+	filenameAsArg       bool
+	optScope            string
+	optExcludeEncrypted bool
 )
 
 func New() *cobra.Command {
@@ -24,18 +28,33 @@ func New() *cobra.Command {
 		RunE:  runner,
 	}
 
+	cmd.Flags().StringVar(&optScope, "scope", string(db.Active), "notes to include: "+db.ScopeNames())
+	cmd.Flags().BoolVar(&optExcludeEncrypted, "exclude-encrypted", false, "skip encrypted (and locked) notes")
 	cmd.Flags().BoolVar(&filenameAsArg, "filename-as-arg", false, "pass filename as arg (default: uuid)")
 
 	return cmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
+	scope, err := db.ParseScope(optScope)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	defer bearDB.Close()
 
+	bearDB.SetScope(scope)
+	bearDB.SetExcludeEncrypted(optExcludeEncrypted)
+
 	allTitles, err := bearDB.QueryAllTitles()
 	if err != nil {
 		return errors.WithStack(err)
@@ -43,23 +62,26 @@ func runner(cmd *cobra.Command, args []string) error {
 
 	items := make([]string, 0)
 
-	for _, t := range allTitles {
-		if !strings.Contains(t.Tags, "captainslog") {
-			tags := strings.Split(t.Tags, ",")
-			filtered := util.RemoveIntermediatePrefixes(tags, "/")
-			tag := strings.Join(filtered, ", ")
-
-			arg := t.ID
-			if filenameAsArg {
-				rec := &db.Record{
-					SHA:   t.NoteSHA,
-					Title: t.Title,
-				}
-				arg = exporter.BuildFilename(rec)
+	for _, t := range cfg.FilterResults(allTitles) {
+		tags := strings.Split(t.Tags, ",")
+		filtered := util.RemoveIntermediatePrefixes(tags, "/")
+		tag := strings.Join(filtered, ", ")
+
+		arg := t.ID
+		if filenameAsArg {
+			rec := &db.Record{
+				SHA:   t.NoteSHA,
+				Title: t.Title,
 			}
+			arg = exporter.BuildFilename(rec)
+		}
 
-			items = append(items, fmt.Sprintf(`{"title":"%s","arg":"%s","subtitle":"%s"}`, t.Title, arg, tag))
+		title := t.Title
+		if t.Encrypted {
+			title = alfred.LockIndicator + title
 		}
+
+		items = append(items, fmt.Sprintf(`{"title":"%s","arg":"%s","subtitle":"%s"}`, title, arg, tag))
 	}
 
 	fmt.Printf(`{"items":[%s]}`, strings.Join(items, ","))
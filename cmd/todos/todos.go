@@ -0,0 +1,248 @@
+package todos
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/alfred"
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/mnadel/freddiebear/todo"
+	"github.com/mnadel/freddiebear/util"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const dueFormat = "2006-01-02"
+
+var (
+	optTag        string
+	optOverdue    bool
+	optFormat     string
+	optBearSyntax bool
+)
+
+// Task is an open todo along with the note it came from
+type Task struct {
+	Text      string   `json:"text"`
+	Heading   string   `json:"heading,omitempty"`
+	Due       string   `json:"due,omitempty"`
+	Overdue   bool     `json:"overdue"`
+	NoteID    string   `json:"note_id"`
+	NoteTitle string   `json:"note_title"`
+	Tags      []string `json:"tags"`
+
+	due time.Time
+}
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "todos [query]",
+		Short: "List open todos across notes",
+		Long:  "Collect the incomplete todos from every note, optionally filtered by tag, text or due date",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runner,
+	}
+
+	cmd.Flags().StringVar(&optTag, "tag", "", "only include notes with this tag (or a tag nested beneath it)")
+	cmd.Flags().BoolVar(&optOverdue, "overdue", false, "only include todos whose due date has passed")
+	cmd.Flags().StringVar(&optFormat, "format", "alfred", "output format: alfred, markdown or json")
+	cmd.Flags().BoolVar(&optBearSyntax, "bear-syntax", false, "also treat - list items as open todos and + items as done (default: todos.bear from config)")
+
+	return cmd
+}
+
+func runner(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if cmd.Flags().Changed("bear-syntax") {
+		cfg.Todos.BearSyntax = optBearSyntax
+	}
+
+	bearDB, err := db.NewDB()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer bearDB.Close()
+
+	records, err := bearDB.QueryOpenTodos()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	query := ""
+	if len(args) == 1 {
+		query = args[0]
+	}
+
+	filter := &Filter{Tag: optTag, Query: query, Overdue: optOverdue}
+	tasks := filter.Apply(collectTasks(cfg, records, time.Now()))
+
+	var output string
+
+	switch optFormat {
+	case "alfred":
+		output, err = alfred.AlfredJSON(alfredItems(tasks))
+	case "markdown":
+		output = markdown(tasks)
+	case "json":
+		var data []byte
+		data, err = json.MarshalIndent(tasks, "", "  ")
+		output = string(data) + "\n"
+	default:
+		return fmt.Errorf("unknown format: %s", optFormat)
+	}
+
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	fmt.Print(output)
+
+	return nil
+}
+
+// collectTasks parses the open todos out of each (non-excluded) note. Tasks with due dates come first,
+// soonest first; the rest keep the notes' order (most recently modified first).
+func collectTasks(cfg *config.Config, records []*db.Record, now time.Time) []*Task {
+	tasks := make([]*Task, 0)
+
+	for _, record := range records {
+		tags := util.RemoveIntermediatePrefixes(db.SplitTags(record.Tags), "/")
+		if cfg.ExcludesNote(record.Title, tags) {
+			continue
+		}
+
+		for _, item := range todo.Incomplete(todo.Parse([]byte(record.Text), cfg.Todos.BearSyntax)) {
+			task := &Task{
+				Text:      item.Text,
+				Heading:   item.Heading,
+				Overdue:   item.Overdue(now),
+				NoteID:    record.ID,
+				NoteTitle: record.Title,
+				Tags:      tags,
+				due:       item.Due,
+			}
+
+			if !item.Due.IsZero() {
+				task.Due = item.Due.Format(dueFormat)
+			}
+
+			tasks = append(tasks, task)
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].due.IsZero() || tasks[j].due.IsZero() {
+			return !tasks[i].due.IsZero() && tasks[j].due.IsZero()
+		}
+		return tasks[i].due.Before(tasks[j].due)
+	})
+
+	return tasks
+}
+
+// Filter selects tasks by their note's tag, their text (or their note's title), and whether they're overdue
+type Filter struct {
+	Tag     string
+	Query   string
+	Overdue bool
+}
+
+// Apply returns the tasks that match the filter
+func (f *Filter) Apply(tasks []*Task) []*Task {
+	filtered := make([]*Task, 0, len(tasks))
+	tag := strings.TrimPrefix(f.Tag, "#")
+	query := strings.ToLower(f.Query)
+
+	for _, task := range tasks {
+		if tag != "" && !hasTag(task.Tags, tag) {
+			continue
+		}
+
+		if query != "" && !strings.Contains(strings.ToLower(task.Text), query) && !strings.Contains(strings.ToLower(task.NoteTitle), query) {
+			continue
+		}
+
+		if f.Overdue && !task.Overdue {
+			continue
+		}
+
+		filtered = append(filtered, task)
+	}
+
+	return filtered
+}
+
+func alfredItems(tasks []*Task) []*alfred.Item {
+	items := make([]*alfred.Item, 0, len(tasks))
+
+	for _, task := range tasks {
+		subtitle := task.NoteTitle
+		if task.Heading != "" && task.Heading != task.NoteTitle {
+			subtitle += " › " + task.Heading
+		}
+		if task.Overdue {
+			subtitle += " · overdue " + task.Due
+		} else if task.Due != "" {
+			subtitle += " · due " + task.Due
+		}
+
+		items = append(items, &alfred.Item{
+			Title:    task.Text,
+			Subtitle: subtitle,
+			Arg:      task.NoteID,
+			Valid:    true,
+		})
+	}
+
+	if len(items) == 0 {
+		items = append(items, &alfred.Item{Title: "No open todos"})
+	}
+
+	return items
+}
+
+// markdown renders the tasks as task lists grouped under a heading (and wikilink) for each note, in
+// the order the notes first appear
+func markdown(tasks []*Task) string {
+	order := make([]string, 0)
+	byNote := make(map[string][]*todo.Item)
+	titles := make(map[string]string)
+
+	for _, task := range tasks {
+		if _, ok := byNote[task.NoteID]; !ok {
+			order = append(order, task.NoteID)
+			titles[task.NoteID] = task.NoteTitle
+		}
+		byNote[task.NoteID] = append(byNote[task.NoteID], &todo.Item{Text: task.Text})
+	}
+
+	b := strings.Builder{}
+
+	for i, id := range order {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## [[%s]]\n", titles[id])
+		b.WriteString(todo.Markdown(byNote[id]))
+	}
+
+	return b.String()
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+
+	return false
+}
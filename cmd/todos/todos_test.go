@@ -0,0 +1,104 @@
+package todos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRecords() []*db.Record {
+	return []*db.Record{
+		{ID: "n1", Title: "Groceries", Tags: "home,home/errands", Text: "# Groceries\n- [ ] milk\n- [x] eggs\n"},
+		{ID: "n2", Title: "Q2 Planning", Tags: "work", Text: "# Q2 Planning\n## Hiring\n- [ ] write job post @due(2024-04-10)\n- [ ] review budget 📅 2024-05-01\n"},
+		{ID: "n3", Title: "Secret", Tags: "private", Text: "- [ ] hidden\n"},
+		{ID: "n4", Title: "Taxes", Tags: "home", Text: "- [ ] file @due(2024-04-15)\n"},
+	}
+}
+
+func testConfig() *config.Config {
+	cfg := config.Default()
+	cfg.Exclude.Tags = []string{"private"}
+	return cfg
+}
+
+func TestCollectTasks(t *testing.T) {
+	now := time.Date(2024, 4, 12, 9, 0, 0, 0, time.Local)
+	tasks := collectTasks(testConfig(), testRecords(), now)
+
+	assert.Equal(t, 4, len(tasks))
+
+	// tasks with due dates come first, soonest first
+	assert.Equal(t, "write job post @due(2024-04-10)", tasks[0].Text)
+	assert.Equal(t, "2024-04-10", tasks[0].Due)
+	assert.True(t, tasks[0].Overdue)
+	assert.Equal(t, "Hiring", tasks[0].Heading)
+	assert.Equal(t, "n2", tasks[0].NoteID)
+	assert.Equal(t, "Q2 Planning", tasks[0].NoteTitle)
+
+	assert.Equal(t, "file @due(2024-04-15)", tasks[1].Text)
+	assert.False(t, tasks[1].Overdue)
+	assert.Equal(t, "review budget 📅 2024-05-01", tasks[2].Text)
+
+	assert.Equal(t, "milk", tasks[3].Text)
+	assert.Equal(t, "", tasks[3].Due)
+	assert.Equal(t, []string{"home/errands"}, tasks[3].Tags)
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2024, 4, 12, 9, 0, 0, 0, time.Local)
+	tasks := collectTasks(testConfig(), testRecords(), now)
+
+	texts := func(tasks []*Task) []string {
+		t := make([]string, 0)
+		for _, task := range tasks {
+			t = append(t, task.Text)
+		}
+		return t
+	}
+
+	assert.Equal(t, []string{"file @due(2024-04-15)", "milk"}, texts((&Filter{Tag: "#home"}).Apply(tasks)))
+	assert.Equal(t, []string{"milk"}, texts((&Filter{Tag: "home/errands"}).Apply(tasks)))
+	assert.Empty(t, (&Filter{Tag: "hom"}).Apply(tasks))
+	assert.Equal(t, []string{"write job post @due(2024-04-10)"}, texts((&Filter{Overdue: true}).Apply(tasks)))
+	assert.Equal(t, []string{"write job post @due(2024-04-10)", "review budget 📅 2024-05-01"}, texts((&Filter{Query: "planning"}).Apply(tasks)))
+	assert.Equal(t, []string{"milk"}, texts((&Filter{Query: "MILK"}).Apply(tasks)))
+	assert.Equal(t, 4, len((&Filter{}).Apply(tasks)))
+}
+
+func TestMarkdown(t *testing.T) {
+	now := time.Date(2024, 4, 12, 9, 0, 0, 0, time.Local)
+	tasks := collectTasks(testConfig(), testRecords(), now)
+
+	expected := `## [[Q2 Planning]]
+- [ ] write job post @due(2024-04-10)
+- [ ] review budget 📅 2024-05-01
+
+## [[Taxes]]
+- [ ] file @due(2024-04-15)
+
+## [[Groceries]]
+- [ ] milk
+`
+
+	assert.Equal(t, expected, markdown(tasks))
+	assert.Equal(t, "", markdown(nil))
+}
+
+func TestAlfredItems(t *testing.T) {
+	now := time.Date(2024, 4, 12, 9, 0, 0, 0, time.Local)
+	items := alfredItems(collectTasks(testConfig(), testRecords(), now))
+
+	assert.Equal(t, "write job post @due(2024-04-10)", items[0].Title)
+	assert.Equal(t, "Q2 Planning › Hiring · overdue 2024-04-10", items[0].Subtitle)
+	assert.Equal(t, "n2", items[0].Arg)
+	assert.True(t, items[0].Valid)
+
+	assert.Equal(t, "Taxes · due 2024-04-15", items[1].Subtitle)
+	assert.Equal(t, "Groceries", items[3].Subtitle)
+
+	assert.Equal(t, "No open todos", alfredItems(nil)[0].Title)
+	assert.False(t, alfredItems(nil)[0].Valid)
+}
@@ -3,29 +3,52 @@ package transcript
 import (
 	"bytes"
 	"fmt"
-	"strings"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/text"
 )
 
+// DefaultSectionLevel is the default heading level that ends a tagged section
+const DefaultSectionLevel = 3
+
 var newline = []byte("\n")
 
+// TagExtractor extracts the tagged sections of a note. A section starts at a heading (or the start of
+// the note), and is tagged if one of the tags (or, with MatchAll, every tag) appears anywhere in its
+// first paragraph. Tags are matched case-sensitively, as they are in Bear's database. A tagged section
+// runs until the next heading whose level is lower than SectionLevel, and is extracted byte-for-byte
+// from the note's source, less its heading and its tags.
 type TagExtractor struct {
-	foundTag bool
-	buf      bytes.Buffer
-	tag      []byte
-	source   []byte
+	// SectionLevel is the heading level that ends a tagged section: headings of a lower level
+	// (i.e. larger headings) end it, while those of this level or higher are part of it
+	SectionLevel int
+	// MatchAll requires a section to be tagged with every tag, rather than any of them
+	MatchAll bool
+
+	buf    bytes.Buffer
+	tags   [][]byte
+	source []byte
 }
 
-func NewTagExtractor(source []byte, tag string) *TagExtractor {
-	return &TagExtractor{
-		foundTag: false,
-		buf:      bytes.Buffer{},
-		tag:      []byte(tag),
-		source:   source,
+// NewTagExtractor creates an extractor for the sections tagged with any of the tags (e.g. `#test`),
+// or tags nested beneath them (e.g. `#test/sub`)
+func NewTagExtractor(source []byte, tags ...string) *TagExtractor {
+	te := &TagExtractor{
+		SectionLevel: DefaultSectionLevel,
+		buf:          bytes.Buffer{},
+		tags:         make([][]byte, 0, len(tags)),
+		source:       source,
+	}
+
+	for _, tag := range tags {
+		te.tags = append(te.tags, []byte(tag))
 	}
+
+	return te
 }
 
 func (te *TagExtractor) ExtractTaggedNotes() []byte {
@@ -37,77 +60,218 @@ func (te *TagExtractor) ExtractTaggedNotes() []byte {
 		return nil
 	}
 
-	ast.Walk(doc, te.visit)
+	// the start of the note is the start of a section, as is every heading
+	node := doc.FirstChild()
+	if end, tagged := te.extractSection(node); tagged {
+		node = end
+	}
+
+	for node != nil {
+		if _, isHeading := node.(*ast.Heading); !isHeading {
+			node = node.NextSibling()
+			continue
+		}
+
+		if end, tagged := te.extractSection(node.NextSibling()); tagged {
+			// skip over the headings nested within the extracted section
+			node = end
+		} else {
+			node = node.NextSibling()
+		}
+	}
 
 	return te.buf.Bytes()
 }
 
-func (te *TagExtractor) visit(node ast.Node, entering bool) (ast.WalkStatus, error) {
-	headingLevel := 0
+// extractSection extracts the section whose first block is first, if it's tagged. It returns the
+// heading that ends the section (nil at the end of the note) and whether the section was tagged.
+func (te *TagExtractor) extractSection(first ast.Node) (ast.Node, bool) {
+	para, isPara := first.(*ast.Paragraph)
+	if !isPara || para.Lines().Len() == 0 {
+		return nil, false
+	}
+
+	lines := para.Lines()
+	start := lineStart(te.source, lines.At(0).Start)
+	end := lineEnd(te.source, lines.At(lines.Len()-1).Start)
 
-	if entering {
-		debug("entering %s, text=%s", node.Kind(), node.Text(te.source))
-	} else {
-		debug("visiting %s", node.Kind())
+	if !te.hasTag(te.source[start:end]) {
+		return nil, false
 	}
 
-	if header, isHeader := node.(*ast.Heading); isHeader {
-		headingLevel = header.Level
-		if te.foundTag && headingLevel < 3 {
-			te.foundTag = false
-			debug("resetting tag state")
+	debug("found tag in %q", te.source[start:end])
+
+	stop := len(te.source)
+	var next ast.Node
+
+	for next = para.NextSibling(); next != nil; next = next.NextSibling() {
+		if heading, isHeading := next.(*ast.Heading); isHeading && heading.Level < te.SectionLevel {
+			stop = headingStart(te.source, heading, end)
+			debug("section ends at %q", te.source[stop:lineEnd(te.source, stop)])
+			break
 		}
-	} else if para, isPara := node.(*ast.Paragraph); isPara {
-		if bytes.Index(para.Text(te.source), te.tag) == 0 {
-			te.foundTag = true
-			debug("found tag")
+	}
+
+	section := te.removeTags(te.source[start:end])
+	section = append(section, te.source[end:stop]...)
+	section = bytes.TrimRight(section, " \t\r\n")
+
+	if len(section) > 0 {
+		te.buf.Write(section)
+		te.buf.Write(newline)
+	}
+
+	return next, true
+}
+
+// hasTag returns true if the paragraph contains one of the extractor's tags, or all of them if MatchAll
+func (te *TagExtractor) hasTag(paragraph []byte) bool {
+	if !te.MatchAll {
+		return containsTag(paragraph, te.tags)
+	}
+
+	for _, tag := range te.tags {
+		if !containsTag(paragraph, [][]byte{tag}) {
+			return false
 		}
-	} else {
-		return ast.WalkContinue, nil
 	}
 
-	if !entering && te.foundTag {
-		te.buf.WriteString(te.nodeText(node, headingLevel))
+	return true
+}
+
+// containsTag returns true if any line of the paragraph contains one of the tags
+func containsTag(paragraph []byte, tags [][]byte) bool {
+	for _, line := range bytes.SplitAfter(paragraph, newline) {
+		if len(findTags(line, tags)) > 0 {
+			return true
+		}
 	}
 
-	return ast.WalkSkipChildren, nil
+	return false
 }
 
-func (te *TagExtractor) nodeText(node ast.Node, headingLevel int) string {
-	s := strings.Builder{}
-	n := node.FirstChild()
+// removeTags removes the extractor's tags from each line of the paragraph, along with any lines that
+// are left blank
+func (te *TagExtractor) removeTags(paragraph []byte) []byte {
+	out := make([]byte, 0, len(paragraph))
 
-	for {
-		if n == nil {
-			break
+	for _, line := range bytes.SplitAfter(paragraph, newline) {
+		matches := findTags(line, te.tags)
+		if len(matches) == 0 {
+			out = append(out, line...)
+			continue
 		}
 
-		text := n.Text(te.source)
+		stripped := make([]byte, 0, len(line))
+		last := 0
+
+		for _, m := range matches {
+			from, to := m[0], m[1]
+			if from > 0 {
+				// take the whitespace before the tag with it
+				for from > last && isBlank(line[from-1]) {
+					from--
+				}
+			} else {
+				for to < len(line) && isBlank(line[to]) {
+					to++
+				}
+			}
 
-		if headingLevel > 0 {
-			s.WriteString(mdHeading(headingLevel))
-			s.WriteString(" ")
+			stripped = append(stripped, line[last:from]...)
+			last = to
 		}
 
-		if !bytes.Equal(text, te.tag) {
-			s.Write(text)
-			s.Write(newline)
+		stripped = append(stripped, line[last:]...)
+
+		if len(bytes.TrimSpace(stripped)) > 0 {
+			out = append(out, stripped...)
+		}
+	}
+
+	return out
+}
+
+// findTags returns the [start, end) offsets of the tags in the line. A tag must be preceded by
+// whitespace (or the start of the line), and may be followed by nested tags: `#test` matches `#test`
+// and `#test/sub`, but not `#testing`, `#Test` or `foo#test`.
+func findTags(line []byte, tags [][]byte) [][]int {
+	matches := make([][]int, 0)
+
+	for i := 0; i < len(line); i++ {
+		if line[i] != '#' || (i > 0 && !isSpace(line, i-1)) {
+			continue
 		}
 
-		n = n.NextSibling()
+		for _, tag := range tags {
+			if !bytes.HasPrefix(line[i:], tag) {
+				continue
+			}
+
+			end := i + len(tag)
+			if end < len(line) && line[end] == '/' {
+				end += tagLength(line[end:])
+			} else if end < len(line) && !isTagEnd(line, end) {
+				continue
+			}
+
+			matches = append(matches, []int{i, end})
+			i = end - 1
+			break
+		}
 	}
 
-	return s.String()
+	return matches
+}
+
+var tagRune = regexp.MustCompile(`^[^\s#]*`)
+
+// tagLength returns the length of the (nested) tag path at the start of b
+func tagLength(b []byte) int {
+	return len(tagRune.Find(b))
+}
+
+// isTagEnd returns true if the tag ends at offset i: at whitespace or trailing punctuation
+func isTagEnd(line []byte, i int) bool {
+	r, _ := utf8.DecodeRune(line[i:])
+	return unicode.IsSpace(r) || (unicode.IsPunct(r) && r != '_' && r != '-' && r != '/')
+}
+
+func isSpace(line []byte, i int) bool {
+	r, _ := utf8.DecodeLastRune(line[:i+1])
+	return unicode.IsSpace(r)
+}
+
+func isBlank(b byte) bool {
+	return b == ' ' || b == '\t'
+}
+
+// lineStart returns the offset of the start of the line containing offset i
+func lineStart(source []byte, i int) int {
+	return bytes.LastIndexByte(source[:i], '\n') + 1
 }
 
-func mdHeading(level int) string {
-	s := strings.Builder{}
+// lineEnd returns the offset just past the end (including the newline) of the line containing offset i
+func lineEnd(source []byte, i int) int {
+	if n := bytes.IndexByte(source[i:], '\n'); n >= 0 {
+		return i + n + 1
+	}
+	return len(source)
+}
+
+// headingStart returns the offset of the start of the heading's first line, searching from offset
+// from for headings without any text (which don't have any source segments)
+func headingStart(source []byte, heading *ast.Heading, from int) int {
+	if heading.Lines().Len() > 0 {
+		return lineStart(source, heading.Lines().At(0).Start)
+	}
 
-	for i := 0; i < level; i++ {
-		s.WriteString("#")
+	empty := regexp.MustCompile(fmt.Sprintf(`(?m)^ {0,3}#{%d}[ \t]*#*[ \t]*$`, heading.Level))
+	if loc := empty.FindIndex(source[from:]); loc != nil {
+		return from + loc[0]
 	}
 
-	return s.String()
+	return len(source)
 }
 
 func debug(msg string, args ...interface{}) {
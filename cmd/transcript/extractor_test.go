@@ -1,11 +1,17 @@
 package transcript
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+var update = flag.Bool("update", false, "update the golden files")
+
 func TestParseMarkdownOneSection(t *testing.T) {
 	markdown := `# Title
 # Test Note
@@ -67,11 +73,117 @@ Even more
 More second sec text
 ### Subsection
 Sub text
-More stuff to extract
-!
+More stuff to extract!
 `
 	ex := NewTagExtractor([]byte(markdown), "#test")
 	n := ex.ExtractTaggedNotes()
 
 	assert.Equal(t, extracted, string(n))
 }
+
+func TestParseMarkdownMultipleAndNestedTags(t *testing.T) {
+	markdown := `# 2024-03-01
+## Standup
+#work/standup
+Standup notes
+
+## Lunch
+#testing
+Not a match
+
+## 1:1
+#people
+1:1 notes
+`
+
+	extracted := `Standup notes
+1:1 notes
+`
+	ex := NewTagExtractor([]byte(markdown), "#work", "#people")
+	n := ex.ExtractTaggedNotes()
+
+	assert.Equal(t, extracted, string(n))
+}
+
+func TestParseMarkdownMatchAll(t *testing.T) {
+	markdown := `# 2024-03-01
+## Standup
+#work/standup #people
+Standup notes
+
+## Planning
+#work
+Planning notes
+
+## 1:1
+#people
+1:1 notes
+`
+
+	ex := NewTagExtractor([]byte(markdown), "#work", "#people")
+	ex.MatchAll = true
+	n := ex.ExtractTaggedNotes()
+
+	assert.Equal(t, "Standup notes\n", string(n))
+}
+
+func TestParseMarkdownMatchesTagCase(t *testing.T) {
+	markdown := `## Standup
+#Work
+Standup notes
+
+## Planning
+#work
+Planning notes
+`
+
+	ex := NewTagExtractor([]byte(markdown), "#work")
+	n := ex.ExtractTaggedNotes()
+
+	assert.Equal(t, "Planning notes\n", string(n))
+}
+
+// TestGolden extracts the #work sections from each note in testdata, comparing them to the
+// corresponding .golden file. Run with -update to regenerate the golden files.
+func TestGolden(t *testing.T) {
+	notes, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, notes)
+
+	for _, note := range notes {
+		t.Run(filepath.Base(note), func(t *testing.T) {
+			source, err := os.ReadFile(note)
+			assert.NoError(t, err)
+
+			extracted := NewTagExtractor(source, "#work").ExtractTaggedNotes()
+			golden := strings.TrimSuffix(note, ".md") + ".golden"
+
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, extracted, 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(extracted))
+		})
+	}
+}
+
+func TestSectionLevel(t *testing.T) {
+	markdown := `# Note
+## Section
+#test
+Section text
+### Subsection
+Sub text
+## Next
+`
+
+	ex := NewTagExtractor([]byte(markdown), "#test")
+	ex.SectionLevel = 4
+	assert.Equal(t, "Section text\n", string(ex.ExtractTaggedNotes()))
+
+	ex = NewTagExtractor([]byte(markdown), "#test")
+	ex.SectionLevel = 2
+	assert.Equal(t, "Section text\n### Subsection\nSub text\n## Next\n", string(ex.ExtractTaggedNotes()))
+}
@@ -0,0 +1,160 @@
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/mnadel/freddiebear/bearurl"
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"github.com/yuin/goldmark"
+)
+
+// Transcript is the collection of tagged sections extracted from a set of notes
+type Transcript struct {
+	Title   string   `json:"title"`
+	Tags    []string `json:"tags"`
+	Entries []*Entry `json:"entries"`
+}
+
+// Entry is the tagged sections extracted from a single note
+type Entry struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Modified string `json:"modified"`
+	Body     string `json:"body"`
+}
+
+// newTranscript extracts the tagged sections from each record, skipping records without any
+func newTranscript(title string, tags []string, records []*db.Record, extract func(*db.Record) []byte) *Transcript {
+	t := &Transcript{Title: title, Tags: tags, Entries: make([]*Entry, 0, len(records))}
+
+	for _, record := range records {
+		body := extract(record)
+		if len(bytes.TrimSpace(body)) == 0 {
+			continue
+		}
+
+		t.Entries = append(t.Entries, &Entry{
+			ID:       record.ID,
+			Title:    record.Title,
+			Modified: record.ModificationDate,
+			Body:     string(body),
+		})
+	}
+
+	return t
+}
+
+// Markdown renders the transcript's body, with a section per note headed by a back-link to it
+func (t *Transcript) Markdown() string {
+	b := strings.Builder{}
+
+	for i, entry := range t.Entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## [[%s]]\n", entry.Title)
+		fmt.Fprintf(&b, "_%s_\n", entry.Modified)
+		b.WriteString(entry.Body)
+	}
+
+	return b.String()
+}
+
+// HTML renders the transcript as a standalone HTML document
+func (t *Transcript) HTML() (string, error) {
+	body := bytes.Buffer{}
+	if err := goldmark.Convert([]byte(t.Markdown()), &body); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	title := html.EscapeString(t.Title)
+
+	return fmt.Sprintf("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n%s</body>\n</html>\n", title, title, body.String()), nil
+}
+
+// JSON renders the transcript and its entries as JSON
+func (t *Transcript) JSON() (string, error) {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(data) + "\n", nil
+}
+
+// BearURL returns a bear:// x-callback-url that creates a new note containing the transcript
+func (t *Transcript) BearURL() string {
+	return bearurl.Create{
+		Title: t.Title,
+		Text:  t.Markdown(),
+		Tags:  t.Tags,
+	}.String()
+}
+
+// Render renders the transcript in the given format: markdown, html or json
+func (t *Transcript) Render(format string) (string, error) {
+	switch format {
+	case "markdown":
+		return t.Markdown(), nil
+	case "html":
+		return t.HTML()
+	case "json":
+		return t.JSON()
+	default:
+		return "", fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// writeOutput sends the rendered transcript to the output: stdout, bear-url or file
+func writeOutput(t *Transcript, output, format, filename string) error {
+	switch output {
+	case "stdout":
+		rendered, err := t.Render(format)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Print(rendered)
+	case "bear-url":
+		if format != "markdown" {
+			return fmt.Errorf("bear-url output requires markdown format")
+		}
+		fmt.Println(t.BearURL())
+	case "file":
+		rendered, err := t.Render(format)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if filename == "" {
+			filename = defaultFilename(t.Title, format)
+		}
+		if err := os.WriteFile(filename, []byte(rendered), 0644); err != nil {
+			return errors.WithStack(err)
+		}
+		fmt.Println(filename)
+	default:
+		return fmt.Errorf("unknown output: %s", output)
+	}
+
+	return nil
+}
+
+// defaultFilename derives a filename from the transcript's title
+func defaultFilename(title, format string) string {
+	ext := map[string]string{"markdown": ".md", "html": ".html", "json": ".json"}[format]
+	name := strings.Map(func(r rune) rune {
+		if r == '/' {
+			return '-'
+		} else if strings.ContainsRune(`\:*?"<>|#`, r) {
+			return -1
+		}
+		return r
+	}, title)
+
+	return strings.TrimSpace(name) + ext
+}
@@ -0,0 +1,92 @@
+package transcript
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTranscript() *Transcript {
+	records := []*db.Record{
+		{ID: "a", Title: "2024-03-01", ModificationDate: "2024-03-01 18:00:00", Text: "## Standup\n#work\n- shipped *it*\n"},
+		{ID: "b", Title: "2024-03-02", ModificationDate: "2024-03-02 18:00:00", Text: "## Lunch\nTacos\n"},
+		{ID: "c", Title: "2024-03-03", ModificationDate: "2024-03-03 18:00:00", Text: "## Standup\n#work\nReview & merge\n"},
+	}
+
+	return newTranscript("Transcript: work", []string{"transcript"}, records, func(record *db.Record) []byte {
+		return NewTagExtractor([]byte(record.Text), "#work").ExtractTaggedNotes()
+	})
+}
+
+func TestTranscriptMarkdown(t *testing.T) {
+	tr := testTranscript()
+
+	assert.Equal(t, 2, len(tr.Entries), "notes without tagged sections are skipped")
+
+	expected := `## [[2024-03-01]]
+_2024-03-01 18:00:00_
+- shipped *it*
+
+## [[2024-03-03]]
+_2024-03-03 18:00:00_
+Review & merge
+`
+
+	assert.Equal(t, expected, tr.Markdown())
+}
+
+func TestTranscriptHTML(t *testing.T) {
+	html, err := testTranscript().HTML()
+	assert.NoError(t, err)
+
+	assert.Contains(t, html, "<title>Transcript: work</title>")
+	assert.Contains(t, html, "<h2>[[2024-03-01]]</h2>")
+	assert.Contains(t, html, "<li>shipped <em>it</em></li>")
+	assert.Contains(t, html, "Review &amp; merge")
+}
+
+func TestTranscriptJSON(t *testing.T) {
+	json, err := testTranscript().JSON()
+	assert.NoError(t, err)
+
+	assert.Contains(t, json, `"title": "Transcript: work"`)
+	assert.Contains(t, json, `"id": "a"`)
+	assert.Contains(t, json, `"body": "- shipped *it*\n"`)
+}
+
+func TestTranscriptBearURL(t *testing.T) {
+	tr := testTranscript()
+	bearURL := tr.BearURL()
+
+	assert.True(t, strings.HasPrefix(bearURL, "bear://x-callback-url/create?"))
+	assert.NotContains(t, bearURL, "+", "spaces must be encoded as %20")
+
+	u, err := url.Parse(bearURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "Transcript: work", u.Query().Get("title"))
+	assert.Equal(t, "transcript", u.Query().Get("tags"))
+	assert.Equal(t, tr.Markdown(), u.Query().Get("text"))
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.json")
+
+	assert.NoError(t, writeOutput(testTranscript(), "file", "json", filename))
+
+	data, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"entries"`)
+
+	assert.Error(t, writeOutput(testTranscript(), "bear-url", "html", ""))
+	assert.Error(t, writeOutput(testTranscript(), "printer", "markdown", ""))
+}
+
+func TestDefaultFilename(t *testing.T) {
+	assert.Equal(t, "Transcript work, people.md", defaultFilename("Transcript: work, people", "markdown"))
+	assert.Equal(t, "a-b.html", defaultFilename("a/b", "html"))
+}
@@ -2,61 +2,213 @@ package transcript
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/mnadel/freddiebear/config"
 	"github.com/mnadel/freddiebear/db"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+const dateFormat = "2006-01-02"
+
 var (
-	optAst   bool
-	optDebug bool
+	optAst           bool
+	optDebug         bool
+	optMatch         string
+	optSince         string
+	optUntil         string
+	optChronological bool
+	optSectionLevel  int
+	optOutput        string
+	optFormat        string
+	optFile          string
+	optTitle         string
+	optNoteTags      []string
 )
 
 func New() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "transcript [tag]",
-		Short: "Create a transcript for a tag",
-		Long:  "Generate a date-based transcript for a given tag",
-		Args:  cobra.ExactArgs(1),
+		Use:   "transcript [tag...]",
+		Short: "Create a transcript for one or more tags",
+		Long:  "Generate a date-based transcript of the sections tagged with the given tags (or tags nested beneath them)",
+		Args:  cobra.MinimumNArgs(1),
 		RunE:  runner,
 	}
 
 	cmd.Flags().BoolVar(&optAst, "ast", false, "show representation of the AST")
 	cmd.Flags().BoolVar(&optDebug, "debug", false, "print parsing debug info")
+	cmd.Flags().StringVar(&optMatch, "match", "any", "include notes with any of the tags, or with all of them: any or all")
+	cmd.Flags().StringVar(&optSince, "since", "", "only include notes titled on or after this date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&optUntil, "until", "", "only include notes titled on or before this date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&optChronological, "chronological", false, "oldest notes first (default: newest first)")
+	cmd.Flags().StringVar(&optOutput, "output", "stdout", "where to send the transcript: stdout, bear-url (a link that creates a new note) or file")
+	cmd.Flags().StringVar(&optFormat, "format", "markdown", "transcript format: markdown, html or json")
+	cmd.Flags().StringVar(&optFile, "file", "", "filename for --output file (default: derived from the title)")
+	cmd.Flags().StringVar(&optTitle, "title", "", "title of the transcript (default: Transcript: followed by the tags)")
+	cmd.Flags().StringSliceVar(&optNoteTags, "note-tags", []string{"transcript"}, "tags for the new note created by --output bear-url")
+	cmd.Flags().IntVar(&optSectionLevel, "section-level", DefaultSectionLevel, "headings of a lower level than this end a tagged section")
 
 	return cmd
 }
 
 func runner(cmd *cobra.Command, args []string) error {
-	bearDB, err := db.NewDB()
+	query, err := newQuery(args, optMatch, optSince, optUntil, optChronological)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	cfg, err := config.Load()
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	defer bearDB.Close()
 
-	results, err := bearDB.QueryTag(args[0])
+	if !cmd.Flags().Changed("section-level") && cfg.Transcript.SectionLevel > 0 {
+		optSectionLevel = cfg.Transcript.SectionLevel
+	}
 
+	bearDB, err := db.NewDB()
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	defer bearDB.Close()
+
+	byTag := make(map[string][]*db.Record)
+
+	for _, tag := range query.Tags {
+		byTag[tag], err = bearDB.QueryTag(tag)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	title := optTitle
+	if title == "" {
+		title = "Transcript: " + strings.Join(query.Tags, ", ")
+	}
+
+	transcript := newTranscript(title, optNoteTags, query.Select(byTag), func(record *db.Record) []byte {
+		te := NewTagExtractor([]byte(record.Text), query.hashtags()...)
+		te.SectionLevel = optSectionLevel
+		te.MatchAll = query.MatchAll
+		return te.ExtractTaggedNotes()
+	})
+
+	if optAst {
+		return nil
+	}
+
+	return writeOutput(transcript, optOutput, optFormat, optFile)
+}
+
+// Query selects the notes that make up a transcript
+type Query struct {
+	Tags []string
+	// MatchAll requires notes to have every tag, rather than any of them
+	MatchAll bool
+	// Since and Until bound the date in the notes' titles (inclusive), if non-zero
+	Since time.Time
+	Until time.Time
+	// Chronological orders the notes oldest first, rather than newest first
+	Chronological bool
+}
+
+func newQuery(tags []string, match, since, until string, chronological bool) (*Query, error) {
+	query := &Query{Chronological: chronological}
+
+	for _, tag := range tags {
+		query.Tags = append(query.Tags, strings.TrimPrefix(tag, "#"))
+	}
+
+	switch match {
+	case "any":
+	case "all":
+		query.MatchAll = true
+	default:
+		return nil, fmt.Errorf("unknown match: %s", match)
+	}
+
+	var err error
+
+	if since != "" {
+		if query.Since, err = time.Parse(dateFormat, since); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if until != "" {
+		if query.Until, err = time.Parse(dateFormat, until); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return query, nil
+}
+
+// Select combines the notes found for each tag, keeping those that match the query, in date order
+func (q *Query) Select(byTag map[string][]*db.Record) []*db.Record {
+	counts := make(map[string]int)
+	records := make([]*db.Record, 0)
+
+	for _, tag := range q.Tags {
+		for _, record := range byTag[tag] {
+			if counts[record.ID] == 0 {
+				records = append(records, record)
+			}
+			counts[record.ID]++
+		}
+	}
+
+	selected := make([]*db.Record, 0, len(records))
+
+	for _, record := range records {
+		if q.MatchAll && counts[record.ID] < len(q.Tags) {
+			continue
+		}
+
+		if !q.Since.IsZero() || !q.Until.IsZero() {
+			date, ok := titleDate(record.Title)
+			if !ok || (!q.Since.IsZero() && date.Before(q.Since)) || (!q.Until.IsZero() && date.After(q.Until)) {
+				continue
+			}
+		}
+
+		selected = append(selected, record)
+	}
 
-	transcript := strings.Builder{}
+	// daily notes' titles start with their date, so sorting by title sorts them by date
+	sort.SliceStable(selected, func(i, j int) bool {
+		if q.Chronological {
+			return selected[i].Title < selected[j].Title
+		}
+		return selected[i].Title > selected[j].Title
+	})
 
-	for _, result := range results {
-		transcript.WriteString(fmt.Sprintf("## %s\n", result.Title))
-		transcript.WriteString(fmt.Sprintf("_%s_\n", result.ModificationDate))
+	return selected
+}
+
+func (q *Query) hashtags() []string {
+	hashtags := make([]string, 0, len(q.Tags))
+
+	for _, tag := range q.Tags {
+		hashtags = append(hashtags, "#"+tag)
+	}
 
-		te := NewTagExtractor([]byte(result.Text), "#"+args[0])
-		data := te.ExtractTaggedNotes()
+	return hashtags
+}
 
-		transcript.Write(data)
+// titleDate parses the date that a daily note's title starts with, e.g. `2024-03-01` or `2024-03-01 Retro`
+func titleDate(title string) (time.Time, bool) {
+	if len(title) < len(dateFormat) {
+		return time.Time{}, false
 	}
 
-	if !optAst {
-		fmt.Println(transcript.String())
+	date, err := time.Parse(dateFormat, title[:len(dateFormat)])
+	if err != nil {
+		return time.Time{}, false
 	}
 
-	return nil
+	return date, true
 }
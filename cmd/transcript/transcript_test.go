@@ -0,0 +1,71 @@
+package transcript
+
+import (
+	"testing"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRecordsByTag() map[string][]*db.Record {
+	a := &db.Record{ID: "a", Title: "2024-03-01"}
+	b := &db.Record{ID: "b", Title: "2024-03-05"}
+	c := &db.Record{ID: "c", Title: "2024-02-20"}
+	d := &db.Record{ID: "d", Title: "Project Plan"}
+
+	return map[string][]*db.Record{
+		"work":   {b, a, d},
+		"people": {b, c},
+	}
+}
+
+func titles(records []*db.Record) []string {
+	t := make([]string, 0)
+	for _, r := range records {
+		t = append(t, r.Title)
+	}
+	return t
+}
+
+func TestSelectAny(t *testing.T) {
+	q, err := newQuery([]string{"#work", "people"}, "any", "", "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"work", "people"}, q.Tags)
+	assert.Equal(t, []string{"#work", "#people"}, q.hashtags())
+
+	assert.Equal(t, []string{"Project Plan", "2024-03-05", "2024-03-01", "2024-02-20"}, titles(q.Select(testRecordsByTag())))
+}
+
+func TestSelectAll(t *testing.T) {
+	q, err := newQuery([]string{"work", "people"}, "all", "", "", false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"2024-03-05"}, titles(q.Select(testRecordsByTag())))
+}
+
+func TestSelectDateRange(t *testing.T) {
+	q, err := newQuery([]string{"work", "people"}, "any", "2024-03-01", "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-03-01", "2024-03-05"}, titles(q.Select(testRecordsByTag())))
+
+	q, err = newQuery([]string{"work", "people"}, "any", "", "2024-03-01", false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-03-01", "2024-02-20"}, titles(q.Select(testRecordsByTag())))
+}
+
+func TestNewQueryErrors(t *testing.T) {
+	_, err := newQuery([]string{"work"}, "some", "", "", false)
+	assert.Error(t, err)
+
+	_, err = newQuery([]string{"work"}, "any", "last week", "", false)
+	assert.Error(t, err)
+}
+
+func TestTitleDate(t *testing.T) {
+	date, ok := titleDate("2024-03-01 Retro")
+	assert.True(t, ok)
+	assert.Equal(t, "2024-03-01", date.Format(dateFormat))
+
+	_, ok = titleDate("Retro")
+	assert.False(t, ok)
+}
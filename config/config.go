@@ -0,0 +1,298 @@
+package config
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigFile is the location of the config file, relative to the user's home directory
+	ConfigFile = ".config/freddiebear/config.yaml"
+
+	EnvConfigFile      = "FREDDIEBEAR_CONFIG"
+	EnvJournalTag      = "FREDDIEBEAR_JOURNAL_TAG"
+	EnvJournalDate     = "FREDDIEBEAR_JOURNAL_DATE"
+	EnvExcludeTags     = "FREDDIEBEAR_EXCLUDE_TAGS"
+	EnvExcludeTitles   = "FREDDIEBEAR_EXCLUDE_TITLES"
+	EnvExcludePatterns = "FREDDIEBEAR_EXCLUDE_PATTERNS"
+)
+
+// Config represents the user's freddiebear configuration
+type Config struct {
+	Journal    Journal    `yaml:"journal"`
+	Exclude    Exclude    `yaml:"exclude"`
+	Todos      Todos      `yaml:"todos"`
+	Transcript Transcript `yaml:"transcript"`
+	Capture    Capture    `yaml:"capture"`
+	Export     Export     `yaml:"export"`
+}
+
+// Export configures where notes are exported
+type Export struct {
+	// Directory is the directory that notes are exported to, used to reveal exported notes
+	Directory string `yaml:"directory"`
+}
+
+// Capture configures quick capture
+type Capture struct {
+	// To is the default note to capture into: a title, a note ID, or `journal` for today's daily note
+	To string `yaml:"to"`
+	// Heading is the default heading to capture beneath
+	Heading string `yaml:"heading"`
+	// HeadingLevel is the level of the heading when capturing creates the note. Defaults to 2 (`##`).
+	HeadingLevel int `yaml:"heading_level"`
+}
+
+// Transcript configures how tagged sections are extracted into transcripts
+type Transcript struct {
+	// SectionLevel is the heading level that ends a tagged section: a heading of a lower level
+	// (i.e. a larger heading) ends it. Defaults to 3, so `#` and `##` headings end sections.
+	SectionLevel int `yaml:"section_level"`
+}
+
+// Todos configures how todos are recognized
+type Todos struct {
+	// BearSyntax also treats `- item` as an incomplete todo and `+ item` as a completed one
+	BearSyntax bool `yaml:"bear"`
+}
+
+// Journal configures the daily journal
+type Journal struct {
+	// Tag is added to new journal entries
+	Tag string `yaml:"tag"`
+	// AppendDate appends yyyy/mm to Tag
+	AppendDate bool `yaml:"date"`
+	// Template is the template file used to render new journal entries
+	Template string `yaml:"template"`
+	// Templates maps a weekday (e.g. monday) to the template file used on that day
+	Templates map[string]string `yaml:"templates"`
+	// Periods configures periodic notes, keyed by period (week, month, quarter, year)
+	Periods map[string]Period `yaml:"periods"`
+}
+
+// Period configures a periodic journal note
+type Period struct {
+	// Title is the note's title format, e.g. {isoyear}-W{ww}
+	Title string `yaml:"title"`
+	// Tag is appended to the journal tag when the journal's date is enabled, e.g. {yyyy}/weekly
+	Tag string `yaml:"tag"`
+	// Template is the template file used to render new notes
+	Template string `yaml:"template"`
+}
+
+// Exclude configures which notes and tags are hidden from search, titles, tags, graph and export
+type Exclude struct {
+	// Tags excludes notes with these tags, or any tags nested beneath them
+	Tags []string `yaml:"tags"`
+	// Titles excludes notes whose titles contain any of these (case-insensitive)
+	Titles []string `yaml:"titles"`
+	// Patterns excludes notes whose titles match any of these regular expressions
+	Patterns []string `yaml:"patterns"`
+
+	patterns []*regexp.Regexp
+}
+
+// DefaultExcludedTag is excluded when the config doesn't set exclude.tags, as freddiebear always has
+const DefaultExcludedTag = "captainslog"
+
+// Default returns the configuration used when there's no config file, and the defaults for any settings
+// a config file omits: notes tagged captainslog are excluded
+func Default() *Config {
+	return &Config{
+		Exclude: Exclude{Tags: []string{DefaultExcludedTag}},
+	}
+}
+
+// Load reads the config file (if it exists) and applies any environment overrides
+func Load() (*Config, error) {
+	filename := os.Getenv(EnvConfigFile)
+	if filename == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		filename = path.Join(home, ConfigFile)
+	}
+
+	cfg := Default()
+
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.WithStack(err)
+	} else if err == nil {
+		cfg, err = Parse(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse %s", filename)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return cfg, cfg.compile()
+}
+
+// Parse parses a YAML config. Sections that are omitted retain their default values.
+func Parse(data []byte) (*Config, error) {
+	cfg := Default()
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return cfg, cfg.compile()
+}
+
+// TemplateFile returns the template file for a journal entry on the given day, or the empty
+// string if there isn't one
+func (j *Journal) TemplateFile(day time.Weekday) string {
+	for weekday, file := range j.Templates {
+		if strings.EqualFold(weekday, day.String()) {
+			return ExpandHome(file)
+		}
+	}
+
+	return ExpandHome(j.Template)
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(filename string) string {
+	if filename != "~" && !strings.HasPrefix(filename, "~/") {
+		return filename
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filename
+	}
+
+	return path.Join(home, strings.TrimPrefix(filename, "~"))
+}
+
+// ExcludesTag returns true if the tag, or one of its parents, is excluded
+func (c *Config) ExcludesTag(tag string) bool {
+	for _, ex := range c.Exclude.Tags {
+		if tag == ex || strings.HasPrefix(tag, ex+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExcludesNote returns true if the note should be hidden, given its title and tags
+func (c *Config) ExcludesNote(title string, tags []string) bool {
+	for _, tag := range tags {
+		if c.ExcludesTag(tag) {
+			return true
+		}
+	}
+
+	lower := strings.ToLower(title)
+	for _, ex := range c.Exclude.Titles {
+		if ex != "" && strings.Contains(lower, strings.ToLower(ex)) {
+			return true
+		}
+	}
+
+	for _, re := range c.Exclude.patterns {
+		if re.MatchString(title) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FilterResults removes excluded notes
+func (c *Config) FilterResults(results db.Results) db.Results {
+	filtered := make(db.Results, 0, len(results))
+
+	for _, r := range results {
+		if !c.ExcludesNote(r.Title, strings.Split(r.Tags, ",")) {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return filtered
+}
+
+// FilterTags removes excluded tags
+func (c *Config) FilterTags(tags []string) []string {
+	filtered := make([]string, 0, len(tags))
+
+	for _, t := range tags {
+		if !c.ExcludesTag(t) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered
+}
+
+func (c *Config) applyEnv() error {
+	if v, ok := os.LookupEnv(EnvJournalTag); ok {
+		c.Journal.Tag = v
+	}
+
+	if v, ok := os.LookupEnv(EnvJournalDate); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.Wrapf(err, "invalid %s", EnvJournalDate)
+		}
+		c.Journal.AppendDate = b
+	}
+
+	if v, ok := os.LookupEnv(EnvExcludeTags); ok {
+		c.Exclude.Tags = splitList(v)
+	}
+
+	if v, ok := os.LookupEnv(EnvExcludeTitles); ok {
+		c.Exclude.Titles = splitList(v)
+	}
+
+	// regular expressions may contain commas, so this is a single pattern
+	if v, ok := os.LookupEnv(EnvExcludePatterns); ok {
+		c.Exclude.Patterns = []string{}
+		if v != "" {
+			c.Exclude.Patterns = []string{v}
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) compile() error {
+	c.Exclude.patterns = make([]*regexp.Regexp, 0, len(c.Exclude.Patterns))
+
+	for _, p := range c.Exclude.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return errors.Wrapf(err, "invalid exclude pattern %q", p)
+		}
+		c.Exclude.patterns = append(c.Exclude.patterns, re)
+	}
+
+	return nil
+}
+
+func splitList(s string) []string {
+	list := make([]string, 0)
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
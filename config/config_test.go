@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultExcludesCaptainslog(t *testing.T) {
+	cfg := Default()
+
+	assert.True(t, cfg.ExcludesTag("captainslog"))
+	assert.True(t, cfg.ExcludesNote("2024-01-02", []string{"captainslog/2024/01"}))
+	assert.False(t, cfg.ExcludesNote("2024-01-02", []string{"work"}))
+	assert.Equal(t, "", cfg.Journal.Tag)
+}
+
+func TestParseKeepsDefaultExclusionUnlessOverridden(t *testing.T) {
+	cfg, err := Parse([]byte("journal:\n  tag: diary\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"captainslog"}, cfg.Exclude.Tags)
+
+	cfg, err = Parse([]byte("exclude:\n  tags: []\n"))
+	assert.NoError(t, err)
+	assert.False(t, cfg.ExcludesTag("captainslog"))
+}
+
+func TestExcludesTag(t *testing.T) {
+	cfg := &Config{Exclude: Exclude{Tags: []string{"captainslog"}}}
+
+	assert.True(t, cfg.ExcludesTag("captainslog"))
+	assert.True(t, cfg.ExcludesTag("captainslog/2024/01"))
+	assert.False(t, cfg.ExcludesTag("captainslogs"))
+}
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(`
+journal:
+  tag: diary
+  date: true
+exclude:
+  tags: [diary, private]
+  titles: [scratch]
+  patterns: ['^\d{4}-\d{2}-\d{2}$']
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "diary", cfg.Journal.Tag)
+	assert.True(t, cfg.Journal.AppendDate)
+
+	assert.True(t, cfg.ExcludesNote("anything", []string{"work", "private/health"}))
+	assert.True(t, cfg.ExcludesNote("My Scratch Pad", nil))
+	assert.True(t, cfg.ExcludesNote("2024-01-02", nil))
+	assert.False(t, cfg.ExcludesNote("2024-01-02 retro", []string{"captainslog"}))
+}
+
+func TestParsePartial(t *testing.T) {
+	cfg, err := Parse([]byte("exclude:\n  titles: [scratch]\n"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"captainslog"}, cfg.Exclude.Tags)
+	assert.Equal(t, []string{"scratch"}, cfg.Exclude.Titles)
+	assert.Equal(t, "", cfg.Journal.Tag)
+}
+
+func TestTemplateFile(t *testing.T) {
+	cfg, err := Parse([]byte(`
+journal:
+  template: /tmp/daily.md
+  templates:
+    Monday: ~/monday.md
+`))
+	assert.NoError(t, err)
+
+	home, _ := os.UserHomeDir()
+
+	assert.Equal(t, path.Join(home, "monday.md"), cfg.Journal.TemplateFile(time.Monday))
+	assert.Equal(t, "/tmp/daily.md", cfg.Journal.TemplateFile(time.Tuesday))
+	assert.Equal(t, "", Default().Journal.TemplateFile(time.Monday))
+}
+
+func TestParsePeriods(t *testing.T) {
+	cfg, err := Parse([]byte(`
+journal:
+  periods:
+    week:
+      title: "Week {ww}, {isoyear}"
+      template: /tmp/weekly.md
+`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Week {ww}, {isoyear}", cfg.Journal.Periods["week"].Title)
+	assert.Equal(t, "/tmp/weekly.md", cfg.Journal.Periods["week"].Template)
+	assert.Equal(t, "", cfg.Journal.Periods["week"].Tag)
+}
+
+func TestParseInvalidPattern(t *testing.T) {
+	_, err := Parse([]byte("exclude:\n  patterns: ['(']\n"))
+	assert.Error(t, err)
+}
+
+func TestLoadWithEnv(t *testing.T) {
+	dir := t.TempDir()
+	filename := path.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(filename, []byte("journal:\n  tag: diary\n"), 0644))
+
+	t.Setenv(EnvConfigFile, filename)
+	t.Setenv(EnvExcludeTags, "a, b/c,")
+	t.Setenv(EnvJournalDate, "true")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "diary", cfg.Journal.Tag)
+	assert.True(t, cfg.Journal.AppendDate)
+	assert.Equal(t, []string{"a", "b/c"}, cfg.Exclude.Tags)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	t.Setenv(EnvConfigFile, path.Join(t.TempDir(), "nope.yaml"))
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, Default().Exclude.Tags, cfg.Exclude.Tags)
+}
+
+func TestFilter(t *testing.T) {
+	cfg := &Config{Exclude: Exclude{Tags: []string{"captainslog"}}}
+
+	results := cfg.FilterResults(db.Results{
+		{Title: "a", Tags: "work,captainslog/2024"},
+		{Title: "b", Tags: "work"},
+		{Title: "c", Tags: ""},
+	})
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "b", results[0].Title)
+
+	assert.Equal(t, []string{"work"}, cfg.FilterTags([]string{"captainslog", "work", "captainslog/2024"}))
+}
+
+func TestDefaultIgnoresBearTodoSyntax(t *testing.T) {
+	assert.False(t, Default().Todos.BearSyntax)
+
+	cfg, err := Parse([]byte("todos:\n  bear: true\n"))
+	assert.NoError(t, err)
+	assert.True(t, cfg.Todos.BearSyntax)
+}
@@ -0,0 +1,17 @@
+package daily
+
+import (
+	"time"
+
+	"github.com/mnadel/freddiebear/config"
+)
+
+// TitleFormat is the format of a daily note's title
+const TitleFormat = "2006-01-02"
+
+// Note returns the title of the daily note for the day, and the tag that a new daily note is
+// created with, according to the config (including any journal.periods.day overrides)
+func Note(cfg *config.Config, day time.Time) (string, string) {
+	period := periods["day"].configure(cfg)
+	return period.Title(day), period.Tag(cfg.Journal.Tag, cfg.Journal.AppendDate, day)
+}
@@ -0,0 +1,31 @@
+package daily
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNote(t *testing.T) {
+	cfg := config.Default()
+	day := time.Date(2024, 3, 1, 23, 30, 0, 0, time.Local)
+
+	title, tag := Note(cfg, day)
+	assert.Equal(t, "2024-03-01", title)
+	assert.Equal(t, "", tag)
+
+	cfg.Journal.Tag = "journal"
+	_, tag = Note(cfg, day)
+	assert.Equal(t, "journal", tag)
+
+	cfg.Journal.AppendDate = true
+	_, tag = Note(cfg, day)
+	assert.Equal(t, "journal/2024/03", tag)
+
+	cfg.Journal.Periods = map[string]config.Period{"day": {Title: "{dd}.{mm}.{yyyy}", Tag: "{yyyy}/daily"}}
+	title, tag = Note(cfg, day)
+	assert.Equal(t, "2024-03-01", title)
+	assert.Equal(t, "journal/2024/daily", tag)
+}
@@ -0,0 +1,183 @@
+package daily
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/pkg/errors"
+)
+
+// Period is the span of time covered by a journal note: a day, week, month, quarter or year
+type Period struct {
+	Name string
+	// TitleFormat and TagFormat use the placeholders {yyyy}, {mm}, {dd}, {q}, {isoyear} and {ww}
+	TitleFormat string
+	TagFormat   string
+	// Template is the template file for new notes, overriding journal.template
+	Template string
+
+	start func(t time.Time) time.Time
+	next  func(start time.Time) time.Time
+}
+
+var periods = map[string]*Period{
+	"day": {
+		Name:        "day",
+		TitleFormat: "{yyyy}-{mm}-{dd}",
+		TagFormat:   "{yyyy}/{mm}",
+		start: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		},
+		next: func(start time.Time) time.Time {
+			return start.AddDate(0, 0, 1)
+		},
+	},
+	"week": {
+		Name:        "week",
+		TitleFormat: "{isoyear}-W{ww}",
+		TagFormat:   "{isoyear}/weekly",
+		start: func(t time.Time) time.Time {
+			offset := (int(t.Weekday()) + 6) % 7
+			return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+		},
+		next: func(start time.Time) time.Time {
+			return start.AddDate(0, 0, 7)
+		},
+	},
+	"month": {
+		Name:        "month",
+		TitleFormat: "{yyyy}-{mm}",
+		TagFormat:   "{yyyy}/monthly",
+		start: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		},
+		next: func(start time.Time) time.Time {
+			return start.AddDate(0, 1, 0)
+		},
+	},
+	"quarter": {
+		Name:        "quarter",
+		TitleFormat: "{yyyy}-Q{q}",
+		TagFormat:   "{yyyy}/quarterly",
+		start: func(t time.Time) time.Time {
+			month := time.Month((int(t.Month())-1)/3*3 + 1)
+			return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+		},
+		next: func(start time.Time) time.Time {
+			return start.AddDate(0, 3, 0)
+		},
+	},
+	"year": {
+		Name:        "year",
+		TitleFormat: "{yyyy}",
+		TagFormat:   "yearly",
+		start: func(t time.Time) time.Time {
+			return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, t.Location())
+		},
+		next: func(start time.Time) time.Time {
+			return start.AddDate(1, 0, 0)
+		},
+	},
+}
+
+// LookupPeriod returns the named period, with any title, tag and template overrides from the config
+func LookupPeriod(name string, cfg *config.Config) (*Period, error) {
+	p, ok := periods[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown period %q (expected one of %s)", name, strings.Join(periodNames(), ", "))
+	}
+
+	period := p.configure(cfg)
+	if period.TitleFormat == "" {
+		return nil, errors.Errorf("empty title format for period %s", name)
+	}
+
+	return period, nil
+}
+
+// BuiltinPeriod returns the named period without any overrides from the config, or nil if there's no such period
+func BuiltinPeriod(name string) *Period {
+	return periods[name]
+}
+
+func (p *Period) configure(cfg *config.Config) *Period {
+	period := *p
+
+	if override, ok := cfg.Journal.Periods[p.Name]; ok {
+		// daily titles are fixed, everything else (e.g. `journal stats`) depends on them
+		if override.Title != "" && p.Name != "day" {
+			period.TitleFormat = override.Title
+		}
+		if override.Tag != "" {
+			period.TagFormat = override.Tag
+		}
+		period.Template = config.ExpandHome(override.Template)
+	}
+
+	return &period
+}
+
+// Start returns the beginning of the period that contains t
+func (p *Period) Start(t time.Time) time.Time {
+	return p.start(t)
+}
+
+// End returns the beginning of the period that follows the one containing t
+func (p *Period) End(t time.Time) time.Time {
+	return p.next(p.start(t))
+}
+
+// Previous returns the beginning of the period before the one containing t
+func (p *Period) Previous(t time.Time) time.Time {
+	return p.start(p.start(t).Add(-time.Nanosecond))
+}
+
+// Title returns the title of the note for the period containing t
+func (p *Period) Title(t time.Time) string {
+	return formatPeriod(p.TitleFormat, p.start(t))
+}
+
+// Tag returns the tag for the note for the period containing t
+func (p *Period) Tag(tag string, appendDate bool, t time.Time) string {
+	if tag == "" && !appendDate {
+		return ""
+	} else if !appendDate {
+		return tag
+	} else {
+		return fmt.Sprintf("%s/%s", tag, formatPeriod(p.TagFormat, p.start(t)))
+	}
+}
+
+// TemplateFile returns the template for a new note, falling back to journal.template(s) for daily notes
+func (p *Period) TemplateFile(cfg *config.Config, t time.Time) string {
+	if p.Template != "" || p.Name != "day" {
+		return p.Template
+	}
+
+	return cfg.Journal.TemplateFile(t.Weekday())
+}
+
+func formatPeriod(format string, t time.Time) string {
+	isoYear, isoWeek := t.ISOWeek()
+
+	return strings.NewReplacer(
+		"{yyyy}", fmt.Sprintf("%04d", t.Year()),
+		"{mm}", fmt.Sprintf("%02d", int(t.Month())),
+		"{dd}", fmt.Sprintf("%02d", t.Day()),
+		"{q}", fmt.Sprintf("%d", (int(t.Month())-1)/3+1),
+		"{isoyear}", fmt.Sprintf("%04d", isoYear),
+		"{ww}", fmt.Sprintf("%02d", isoWeek),
+	).Replace(format)
+}
+
+func periodNames() []string {
+	names := make([]string, 0, len(periods))
+	for name := range periods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package daily
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mnadel/freddiebear/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeriodTitles(t *testing.T) {
+	day := time.Date(2024, 2, 14, 15, 30, 0, 0, time.Local)
+
+	assert.Equal(t, "2024-02-14", BuiltinPeriod("day").Title(day))
+	assert.Equal(t, "2024-W07", BuiltinPeriod("week").Title(day))
+	assert.Equal(t, "2024-02", BuiltinPeriod("month").Title(day))
+	assert.Equal(t, "2024-Q1", BuiltinPeriod("quarter").Title(day))
+	assert.Equal(t, "2024", BuiltinPeriod("year").Title(day))
+
+	// the ISO week belongs to the following year
+	assert.Equal(t, "2025-W01", BuiltinPeriod("week").Title(time.Date(2024, 12, 31, 0, 0, 0, 0, time.Local)))
+}
+
+func TestPeriodBoundaries(t *testing.T) {
+	day := time.Date(2024, 2, 14, 15, 30, 0, 0, time.Local)
+
+	week := BuiltinPeriod("week")
+	assert.Equal(t, time.Date(2024, 2, 12, 0, 0, 0, 0, time.Local), week.Start(day))
+	assert.Equal(t, time.Date(2024, 2, 19, 0, 0, 0, 0, time.Local), week.End(day))
+	assert.Equal(t, time.Date(2024, 2, 5, 0, 0, 0, 0, time.Local), week.Previous(day))
+
+	// sundays belong to the week that started on the previous monday
+	sunday := time.Date(2024, 2, 18, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2024, 2, 12, 0, 0, 0, 0, time.Local), week.Start(sunday))
+
+	quarter := BuiltinPeriod("quarter")
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), quarter.Start(day))
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local), quarter.End(day))
+	assert.Equal(t, "2023-Q4", quarter.Title(quarter.Previous(day)))
+}
+
+func TestPeriodTag(t *testing.T) {
+	day := time.Date(2024, 2, 14, 0, 0, 0, 0, time.Local)
+
+	assert.Equal(t, "", BuiltinPeriod("day").Tag("", false, day))
+	assert.Equal(t, "log", BuiltinPeriod("day").Tag("log", false, day))
+	assert.Equal(t, "log/2024/02", BuiltinPeriod("day").Tag("log", true, day))
+	assert.Equal(t, "log/2024/weekly", BuiltinPeriod("week").Tag("log", true, day))
+	assert.Equal(t, "log/yearly", BuiltinPeriod("year").Tag("log", true, day))
+}
+
+func TestLookupPeriod(t *testing.T) {
+	cfg := &config.Config{Journal: config.Journal{
+		Template: "/tmp/daily.md",
+		Periods: map[string]config.Period{
+			"week": {Title: "Week {ww} of {isoyear}", Template: "/tmp/weekly.md"},
+			"day":  {Title: "{dd}.{mm}.{yyyy}"},
+		},
+	}}
+
+	week, err := LookupPeriod("week", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "Week 07 of 2024", week.Title(time.Date(2024, 2, 14, 0, 0, 0, 0, time.Local)))
+	assert.Equal(t, "/tmp/weekly.md", week.TemplateFile(cfg, time.Now()))
+	assert.Equal(t, "{isoyear}-W{ww}", BuiltinPeriod("week").TitleFormat)
+
+	day, err := LookupPeriod("day", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "{yyyy}-{mm}-{dd}", day.TitleFormat)
+	assert.Equal(t, "/tmp/daily.md", day.TemplateFile(cfg, time.Now()))
+
+	month, err := LookupPeriod("month", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "", month.TemplateFile(cfg, time.Now()))
+
+	_, err = LookupPeriod("fortnight", cfg)
+	assert.Error(t, err)
+}
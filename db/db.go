@@ -15,7 +15,10 @@ import (
 )
 
 const (
-	dbFile = `/Library/Group Containers/9K33E3U3T4.net.shinyfrog.bear/Application Data/database.sqlite?mode=ro`
+	// DataDirectory is Bear's data directory, relative to the user's home directory
+	DataDirectory = `Library/Group Containers/9K33E3U3T4.net.shinyfrog.bear/Application Data`
+
+	dbFile = `/` + DataDirectory + `/database.sqlite?mode=ro`
 
 	sqlDeletedAttachments = `
 		SELECT
@@ -34,15 +37,24 @@ const (
 			note.ZUNIQUEIDENTIFIER,
 			note.ZTITLE,
 			datetime(note.ZMODIFICATIONDATE, 'unixepoch', '31 years', 'localtime') as mod_date,
-			note.ZTEXT
+			{{text note}},
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, ''))
 		FROM
 			ZSFNOTE note
 			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
 			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
 		WHERE
-			note.ZARCHIVED = 0
-			AND note.ZTRASHED = 0
-			AND tag.ZTITLE = ?
+			{{scope note}}
+			AND note.Z_PK IN (
+				SELECT tagged.Z_5NOTES
+				FROM
+					Z_5TAGS tagged
+					JOIN ZSFNOTETAG t ON tagged.Z_13TAGS = t.Z_PK
+				WHERE
+					t.ZTITLE = ?1 OR substr(t.ZTITLE, 1, length(?1) + 1) = ?1 || '/'
+			)
+		GROUP BY
+			note.ZUNIQUEIDENTIFIER
 		ORDER BY
 			note.ZTITLE DESC
 	`
@@ -56,8 +68,7 @@ const (
 			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
 			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
 		WHERE
-			note.ZARCHIVED = 0
-			AND note.ZTRASHED = 0
+			{{linked note}}
 		GROUP BY
 			note.Z_PK
 	`
@@ -69,23 +80,47 @@ const (
 			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
 			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
 		WHERE
-			note.ZARCHIVED = 0
-			AND note.ZTRASHED = 0
+			{{scope note}}
 			AND tag.ZTITLE IS NOT NULL
 	`
 
+	sqlTagTitles = `
+		SELECT DISTINCT tag.ZTITLE
+		FROM ZSFNOTETAG tag
+		WHERE tag.ZTITLE IS NOT NULL
+		ORDER BY tag.ZTITLE
+	`
+
+	sqlTaggedNotes = `
+		SELECT
+			note.ZUNIQUEIDENTIFIER,
+			note.ZTITLE,
+			datetime(note.ZMODIFICATIONDATE, 'unixepoch', '31 years', 'localtime') as mod_date,
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, ''))
+		FROM
+			ZSFNOTE note
+			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
+			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
+		WHERE
+			{{scope note}}
+		GROUP BY
+			note.ZUNIQUEIDENTIFIER
+		ORDER BY
+			note.ZMODIFICATIONDATE DESC
+	`
+
 	sqlAllTitles = `
 		SELECT DISTINCT
 			note.ZUNIQUEIDENTIFIER,
 			note.ZTITLE,
-			GROUP_CONCAT(COALESCE(tag.ZTITLE, ''))
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, '')),
+			{{encrypted note}}
 		FROM
 			ZSFNOTE note
 			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
 			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
 		WHERE
-			note.ZARCHIVED = 0
-			AND note.ZTRASHED = 0
+			{{scope note}}
 		GROUP BY
 			note.ZUNIQUEIDENTIFIER
 		ORDER BY
@@ -96,49 +131,120 @@ const (
 		SELECT DISTINCT
 			note.ZUNIQUEIDENTIFIER,
 			note.ZTITLE,
-			GROUP_CONCAT(COALESCE(tag.ZTITLE, ''))
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, '')),
+			{{encrypted note}}
 		FROM
 			ZSFNOTE note
 			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
 			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
 		WHERE
-			note.ZARCHIVED = 0
-			AND note.ZTRASHED = 0
+			{{scope note}}
 			AND LOWER(note.ZTITLE) LIKE LOWER(?)
 		GROUP BY
 			note.ZUNIQUEIDENTIFIER
 		ORDER BY
+			COALESCE(note.ZPINNED, 0) DESC,
 			note.ZMODIFICATIONDATE DESC
 	`
 
+	sqlRecordsByTitle = `
+		SELECT
+			note.ZUNIQUEIDENTIFIER,
+			note.ZTITLE,
+			datetime(note.ZMODIFICATIONDATE, 'unixepoch', '31 years', 'localtime') as mod_date,
+			{{text note}}
+		FROM
+			ZSFNOTE note
+		WHERE
+			{{scope note}}
+			AND note.ZTITLE LIKE ?
+		ORDER BY
+			note.ZTITLE DESC
+	`
+
 	sqlText = `
 		SELECT
 			note.ZUNIQUEIDENTIFIER,
 			note.ZTITLE,
-			GROUP_CONCAT(COALESCE(tag.ZTITLE, ''))
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, '')),
+			{{encrypted note}}
 		FROM
 			ZSFNOTE note
 			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
 			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
 		WHERE
-			note.ZARCHIVED = 0
-			AND note.ZTRASHED = 0
-			AND (LOWER(note.ZTEXT) LIKE LOWER(?) OR LOWER(note.ZTITLE) LIKE LOWER(?))
+			{{scope note}}
+			AND (LOWER({{text note}}) LIKE LOWER(?) OR LOWER(note.ZTITLE) LIKE LOWER(?))
 		GROUP BY
 			note.ZUNIQUEIDENTIFIER
 		ORDER BY
+			COALESCE(note.ZPINNED, 0) DESC,
 			note.ZMODIFICATIONDATE DESC
 	`
+	sqlAttachmentText = `
+		SELECT
+			note.ZUNIQUEIDENTIFIER,
+			note.ZTITLE,
+			COALESCE((
+				SELECT GROUP_CONCAT(tag.ZTITLE)
+				FROM
+					Z_5TAGS tags
+					JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
+				WHERE
+					tags.Z_5NOTES = note.Z_PK
+			), ''),
+			COALESCE(f.ZFILENAME, ''),
+			f.ZSEARCHTEXT
+		FROM
+			ZSFNOTEFILE f
+			JOIN ZSFNOTE note ON note.Z_PK = f.ZNOTE
+		WHERE
+			{{scope note}}
+			AND NOT {{encrypted note}}
+			AND COALESCE(f.ZPERMANENTLYDELETED, 0) = 0
+			AND LOWER(f.ZSEARCHTEXT) LIKE LOWER(?)
+		ORDER BY
+			COALESCE(note.ZPINNED, 0) DESC,
+			note.ZMODIFICATIONDATE DESC,
+			f.ZINDEX
+	`
+
 	sqlExport = `
-		select
-			ZUNIQUEIDENTIFIER,
-			ZTITLE,
-			ZTEXT
-		from
-			ZSFNOTE
-		where
-			ZARCHIVED = 0 
-			and ZTRASHED = 0
+		SELECT
+			note.ZUNIQUEIDENTIFIER,
+			note.ZTITLE,
+			datetime(note.ZMODIFICATIONDATE, 'unixepoch', '31 years', 'localtime') as mod_date,
+			{{text note}},
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, '')),
+			{{encrypted note}}
+		FROM
+			ZSFNOTE note
+			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
+			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
+		WHERE
+			{{scope note}}
+		GROUP BY
+			note.ZUNIQUEIDENTIFIER
+	`
+
+	sqlOpenTodos = `
+		SELECT
+			note.ZUNIQUEIDENTIFIER,
+			note.ZTITLE,
+			datetime(note.ZMODIFICATIONDATE, 'unixepoch', '31 years', 'localtime') as mod_date,
+			{{text note}},
+			GROUP_CONCAT(COALESCE(tag.ZTITLE, ''))
+		FROM
+			ZSFNOTE note
+			LEFT OUTER JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES
+			LEFT OUTER JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
+		WHERE
+			{{scope note}}
+			AND note.ZTODOINCOMPLETED > 0
+		GROUP BY
+			note.ZUNIQUEIDENTIFIER
+		ORDER BY
+			note.ZMODIFICATIONDATE DESC
 	`
 
 	sqlGraph = `
@@ -155,10 +261,8 @@ const (
 			JOIN ZSFNOTE src ON src.Z_PK = b.ZLINKINGTO
 			JOIN ZSFNOTE target ON target.Z_PK = b.ZLINKEDBY
 		WHERE
-			src.ZARCHIVED = 0
-			AND src.ZTRASHED = 0
-			AND target.ZARCHIVED = 0
-			AND target.ZTRASHED = 0
+			{{scope src}}
+			AND {{linked target}}
 	`
 
 	sqlAttachments = `
@@ -171,12 +275,68 @@ const (
 			ZSFNOTE n 
 			JOIN ZSFNOTEFILE f on f.ZNOTE = n.Z_PK
 		WHERE
-			n.ZARCHIVED = 0
-			AND n.ZTRASHED = 0
+			{{scope n}}
 		ORDER BY
 			n.ZUNIQUEIDENTIFIER
 	`
 
+	sqlAttachmentInventory = `
+		SELECT
+			COALESCE(n.ZUNIQUEIDENTIFIER, ''),
+			COALESCE(n.ZTITLE, ''),
+			COALESCE((
+				SELECT GROUP_CONCAT(tag.ZTITLE)
+				FROM
+					Z_5TAGS tags
+					JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
+				WHERE
+					tags.Z_5NOTES = n.Z_PK
+			), ''),
+			COALESCE(f.ZUNIQUEIDENTIFIER, ''),
+			COALESCE(f.ZFILENAME, ''),
+			COALESCE(f.ZFILESIZE, 0),
+			COALESCE(f.ZWIDTH, f.ZWIDTH1, 0),
+			COALESCE(f.ZHEIGHT, f.ZHEIGHT1, 0),
+			COALESCE(f.ZNORMALIZEDFILEEXTENSION, ''),
+			COALESCE(datetime(f.ZCREATIONDATE, 'unixepoch', '31 years', 'localtime'), ''),
+			COALESCE(datetime(f.ZINSERTIONDATE, 'unixepoch', '31 years', 'localtime'), ''),
+			COALESCE(f.ZDOWNLOADED, 0),
+			COALESCE(f.ZUPLOADED, 0),
+			COALESCE(n.ZTRASHED, 0)
+		FROM
+			ZSFNOTEFILE f
+			LEFT OUTER JOIN ZSFNOTE n ON n.Z_PK = f.ZNOTE
+		WHERE
+			COALESCE(f.ZPERMANENTLYDELETED, 0) = 0
+	`
+
+	sqlNoteStats = `
+		SELECT
+			note.ZUNIQUEIDENTIFIER,
+			COALESCE(note.ZTITLE, ''),
+			COALESCE(datetime(note.ZCREATIONDATE, 'unixepoch', '31 years', 'localtime'), ''),
+			COALESCE(datetime(note.ZMODIFICATIONDATE, 'unixepoch', '31 years', 'localtime'), ''),
+			COALESCE({{text note}}, ''),
+			COALESCE((
+				SELECT GROUP_CONCAT(tag.ZTITLE)
+				FROM
+					Z_5TAGS tags
+					JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK
+				WHERE
+					tags.Z_5NOTES = note.Z_PK
+			), ''),
+			COALESCE(note.ZPINNED, 0),
+			COALESCE(note.ZARCHIVED, 0),
+			COALESCE(note.ZTRASHED, 0),
+			{{encrypted note}},
+			COALESCE(note.Z_OPT, 0),
+			(SELECT COUNT(*) FROM ZSFNOTEBACKLINK b WHERE b.ZLINKINGTO = note.Z_PK)
+		FROM
+			ZSFNOTE note
+		WHERE
+			COALESCE(note.ZPERMANENTLYDELETED, 0) = 0
+	`
+
 	sqlPragma = `
 		PRAGMA query_only = on;
 		PRAGMA synchronous = off;
@@ -192,23 +352,46 @@ type Exporter func(record *Record) error
 
 // DB represents the Bear Notes database
 type DB struct {
-	db *sql.DB
+	db               *sql.DB
+	schema           *Schema
+	scope            Scope
+	excludeEncrypted bool
 }
 
 // Record represents an exported note
 type Record struct {
+	ID               string
 	SHA              string
 	Title            string
 	Text             string
 	ModificationDate string
+	Tags             string
+	// Encrypted is true if the note is encrypted (or locked), in which case Text is empty
+	Encrypted bool
 }
 
 // Result references a specific note: its identifier and title
 type Result struct {
-	NoteSHA string
-	ID      string
-	Title   string
-	Tags    string
+	NoteSHA   string
+	ID        string
+	Title     string
+	Tags      string
+	Encrypted bool
+}
+
+// TaggedNote is a note along with every tag applied to it
+type TaggedNote struct {
+	ID               string
+	Title            string
+	ModificationDate string
+	Tags             []string
+}
+
+// AttachmentMatch is an attachment whose (OCR or extracted) text matched a search, along with its note
+type AttachmentMatch struct {
+	Note     *Result
+	Filename string
+	Text     string
 }
 
 type Attachment struct {
@@ -218,6 +401,41 @@ type Attachment struct {
 	Filename   string
 }
 
+// AttachmentInfo describes an attachment's storage, along with the note it belongs to
+type AttachmentInfo struct {
+	Attachment
+	NoteID     string
+	Tags       []string
+	Size       int64
+	Width      int
+	Height     int
+	Extension  string
+	Created    string
+	Inserted   string
+	Downloaded bool
+	Uploaded   bool
+	// Trashed is true if the attachment's note is in the trash
+	Trashed bool
+}
+
+// NoteStat is a note's dates, state and content, for reporting on the whole database
+type NoteStat struct {
+	ID        string
+	Title     string
+	Created   string
+	Modified  string
+	Text      string
+	Tags      []string
+	Pinned    bool
+	Archived  bool
+	Trashed   bool
+	Encrypted bool
+	// Saves is the number of times the note has been saved (Core Data's optimistic locking counter)
+	Saves int
+	// Links is the number of links from the note to other notes
+	Links int
+}
+
 // Results is a list of *Result, and represents a collection of notes in the database
 type Results []*Result
 
@@ -235,7 +453,12 @@ func NewDB() (*DB, error) {
 		return nil, errors.WithStack(err)
 	}
 
-	db, err := sql.Open("sqlite3", path.Join(home, dbFile))
+	return newDB(path.Join(home, dbFile))
+}
+
+// newDB opens the database at the data source name
+func newDB(dsn string) (*DB, error) {
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -244,7 +467,13 @@ func NewDB() (*DB, error) {
 		return nil, errors.WithStack(err)
 	}
 
-	return &DB{db}, nil
+	schema, err := detectSchema(db)
+	if err != nil {
+		db.Close()
+		return nil, errors.WithStack(err)
+	}
+
+	return &DB{db: db, schema: schema, scope: Active}, nil
 }
 
 // Close cleans up our database connection
@@ -252,11 +481,32 @@ func (d *DB) Close() error {
 	return d.db.Close()
 }
 
+// SetScope chooses the notes that subsequent queries cover (by default, active notes)
+func (d *DB) SetScope(scope Scope) {
+	d.scope = scope
+}
+
+// SetExcludeEncrypted chooses whether subsequent queries skip encrypted (and locked) notes
+func (d *DB) SetExcludeEncrypted(exclude bool) {
+	d.excludeEncrypted = exclude
+}
+
+// Schema returns the schema detected when the database was opened
+func (d *DB) Schema() *Schema {
+	return d.schema
+}
+
+// query runs the query with its placeholders expanded, e.g. `{{scope alias}}` with the DB's scope, and
+// its join table names rewritten for the database's schema
+func (d *DB) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.db.Query(d.schema.rewrite(d.scope.expand(query, d.excludeEncrypted)), args...)
+}
+
 // AllAttachments returns a list of all attachments in the database.
 func (d *DB) AllAttachments() ([]*Attachment, error) {
 	records := make([]*Attachment, 0)
 
-	rows, err := d.db.Query(sqlAttachments)
+	rows, err := d.query(sqlAttachments)
 	if err != nil {
 		return nil, errors.WithStack(rows.Err())
 	}
@@ -280,27 +530,91 @@ func (d *DB) AllAttachments() ([]*Attachment, error) {
 	return records, nil
 }
 
+// AttachmentInventory returns every attachment that hasn't been permanently deleted, including
+// those of trashed and archived notes
+func (d *DB) AttachmentInventory() ([]*AttachmentInfo, error) {
+	rows, err := d.query(sqlAttachmentInventory)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	attachments := make([]*AttachmentInfo, 0)
+
+	for rows.Next() {
+		var a AttachmentInfo
+		var tags string
+
+		err := rows.Scan(&a.NoteID, &a.NoteTitle, &tags, &a.FolderUUID, &a.Filename, &a.Size, &a.Width, &a.Height,
+			&a.Extension, &a.Created, &a.Inserted, &a.Downloaded, &a.Uploaded, &a.Trashed)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if a.NoteID != "" {
+			a.NoteSHA = guidToSHA(a.NoteID)
+		}
+		a.Tags = SplitTags(tags)
+
+		attachments = append(attachments, &a)
+	}
+
+	return attachments, errors.WithStack(rows.Err())
+}
+
+// NoteStats returns every note that hasn't been permanently deleted, including archived and trashed notes
+func (d *DB) NoteStats() ([]*NoteStat, error) {
+	rows, err := d.query(sqlNoteStats)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	notes := make([]*NoteStat, 0)
+
+	for rows.Next() {
+		var n NoteStat
+		var tags string
+
+		err := rows.Scan(&n.ID, &n.Title, &n.Created, &n.Modified, &n.Text, &tags,
+			&n.Pinned, &n.Archived, &n.Trashed, &n.Encrypted, &n.Saves, &n.Links)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		n.Tags = SplitTags(tags)
+		notes = append(notes, &n)
+	}
+
+	return notes, errors.WithStack(rows.Err())
+}
+
 // Records returns the list of notes in the database
 func (d *DB) Records() ([]*Record, error) {
 	records := make([]*Record, 0)
 
-	rows, err := d.db.Query(sqlExport)
+	rows, err := d.query(sqlExport)
 	if err != nil {
 		return nil, errors.WithStack(rows.Err())
 	}
 
-	var guid, title, text string
+	var guid, title, moddate, text, tags string
+	var encrypted bool
 
 	for rows.Next() {
-		err := rows.Scan(&guid, &title, &text)
+		err := rows.Scan(&guid, &title, &moddate, &text, &tags, &encrypted)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 
 		record := &Record{
-			SHA:   guidToSHA(guid),
-			Title: title,
-			Text:  text,
+			ID:               guid,
+			SHA:              guidToSHA(guid),
+			Title:            title,
+			Text:             text,
+			ModificationDate: moddate,
+			Tags:             tags,
+			Encrypted:        encrypted,
 		}
 
 		records = append(records, record)
@@ -336,7 +650,7 @@ func (d *DB) QueryTitles(term string, exact bool) (Results, error) {
 		bind = substringSearch(term)
 	}
 
-	rows, err := d.db.Query(sqlTitle, bind)
+	rows, err := d.query(sqlTitle, bind)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -345,9 +659,70 @@ func (d *DB) QueryTitles(term string, exact bool) (Results, error) {
 	return rowsToResults(rows)
 }
 
+// QueryRecordsByTitle returns the notes, including their text, whose titles match the
+// SQL LIKE pattern (e.g. `____-__-__` for daily notes)
+func (d *DB) QueryRecordsByTitle(pattern string) ([]*Record, error) {
+	rows, err := d.query(sqlRecordsByTitle, pattern)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	records := make([]*Record, 0)
+	var guid, title, moddate, text string
+
+	for rows.Next() {
+		err := rows.Scan(&guid, &title, &moddate, &text)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		records = append(records, &Record{
+			ID:               guid,
+			SHA:              guidToSHA(guid),
+			Title:            title,
+			Text:             text,
+			ModificationDate: moddate,
+		})
+	}
+
+	return records, errors.WithStack(rows.Err())
+}
+
+// QueryOpenTodos returns the notes, including their text and tags, that Bear reports as having
+// incomplete todos
+func (d *DB) QueryOpenTodos() ([]*Record, error) {
+	rows, err := d.query(sqlOpenTodos)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	records := make([]*Record, 0)
+	var guid, title, moddate, text, tags string
+
+	for rows.Next() {
+		err := rows.Scan(&guid, &title, &moddate, &text, &tags)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		records = append(records, &Record{
+			ID:               guid,
+			SHA:              guidToSHA(guid),
+			Title:            title,
+			Text:             text,
+			ModificationDate: moddate,
+			Tags:             tags,
+		})
+	}
+
+	return records, errors.WithStack(rows.Err())
+}
+
 // QueryAllTitles returns a list of all titles
 func (d *DB) QueryAllTitles() (Results, error) {
-	rows, err := d.db.Query(sqlAllTitles)
+	rows, err := d.query(sqlAllTitles)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -359,7 +734,7 @@ func (d *DB) QueryAllTitles() (Results, error) {
 // QueryText searches for a term within the body or title of notes within the database.
 func (d *DB) QueryText(term string) (Results, error) {
 	bind := substringSearch(term)
-	rows, err := d.db.Query(sqlText, bind, bind)
+	rows, err := d.query(sqlText, bind, bind)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -369,9 +744,42 @@ func (d *DB) QueryText(term string) (Results, error) {
 	return rowsToResults(rows)
 }
 
+// QueryAttachmentText searches for a term within the text Bear extracted from notes' attachments
+// (e.g. by OCR of images and PDFs), returning each matching attachment and its note
+func (d *DB) QueryAttachmentText(term string) ([]*AttachmentMatch, error) {
+	rows, err := d.query(sqlAttachmentText, substringSearch(term))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	matches := make([]*AttachmentMatch, 0)
+	var guid, title, tags, filename, text string
+
+	for rows.Next() {
+		err := rows.Scan(&guid, &title, &tags, &filename, &text)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		matches = append(matches, &AttachmentMatch{
+			Note: &Result{
+				NoteSHA: guidToSHA(guid),
+				ID:      guid,
+				Title:   title,
+				Tags:    tags,
+			},
+			Filename: filename,
+			Text:     text,
+		})
+	}
+
+	return matches, errors.WithStack(rows.Err())
+}
+
 // QueryTags returns a list of all tags
 func (d *DB) QueryTags() ([]string, error) {
-	rows, err := d.db.Query(sqlAllTags)
+	rows, err := d.query(sqlAllTags)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -393,9 +801,59 @@ func (d *DB) QueryTags() ([]string, error) {
 	return util.RemoveIntermediatePrefixes(tags, "/"), nil
 }
 
+// QueryTagTitles returns every tag, including intermediate tags and those without any notes
+func (d *DB) QueryTagTitles() ([]string, error) {
+	rows, err := d.query(sqlTagTitles)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	var tag string
+
+	for rows.Next() {
+		if err := rows.Scan(&tag); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, errors.WithStack(rows.Err())
+}
+
+// QueryTaggedNotes returns every note along with its full set of tags (including intermediate tags)
+func (d *DB) QueryTaggedNotes() ([]*TaggedNote, error) {
+	rows, err := d.query(sqlTaggedNotes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	notes := make([]*TaggedNote, 0)
+	var id, title, moddate, tags string
+
+	for rows.Next() {
+		err := rows.Scan(&id, &title, &moddate, &tags)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		notes = append(notes, &TaggedNote{
+			ID:               id,
+			Title:            title,
+			ModificationDate: moddate,
+			Tags:             SplitTags(tags),
+		})
+	}
+
+	return notes, errors.WithStack(rows.Err())
+}
+
 // QueryDeletedAttachments returns a list of attachments that can be deleted off disk.
 func (d *DB) QueryDeletedAttachments() ([]*Attachment, error) {
-	rows, err := d.db.Query(sqlDeletedAttachments)
+	rows, err := d.query(sqlDeletedAttachments)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -417,35 +875,46 @@ func (d *DB) QueryDeletedAttachments() ([]*Attachment, error) {
 	return attachments, nil
 }
 
-// QueryTag searches for all notes with a given tag within the database.
+// QueryTag searches for all notes with a given tag, or a tag nested beneath it, within the database.
+// Tags are matched case-sensitively, as they are in the notes' text.
 func (d *DB) QueryTag(tag string) ([]*Record, error) {
-	rows, err := d.db.Query(sqlNotesByTag, tag)
+	rows, err := d.query(sqlNotesByTag, tag)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	defer rows.Close()
 
 	records := make([]*Record, 0)
-	var guid, title, text, moddate string
+	var guid, title, text, moddate, tags string
 
 	for rows.Next() {
-		err := rows.Scan(&guid, &title, &moddate, &text)
+		err := rows.Scan(&guid, &title, &moddate, &text, &tags)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
-		records = append(records, &Record{guid, title, text, moddate})
+		records = append(records, &Record{
+			ID:               guid,
+			SHA:              guidToSHA(guid),
+			Title:            title,
+			Text:             text,
+			ModificationDate: moddate,
+			Tags:             tags,
+		})
 	}
 
-	return records, nil
+	return records, errors.WithStack(rows.Err())
 }
 
-// QueryGraph returns a graph of linked notes
+// QueryGraph returns a graph of the links from the notes in scope to the notes they're linked to: other
+// active notes for the active and pinned scopes, and any note that hasn't been permanently deleted for
+// the others
 func (d *DB) QueryGraph() (Graph, error) {
 	tags, err := d.tagsByNoteID()
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	rows, err := d.db.Query(sqlGraph)
+	rows, err := d.query(sqlGraph)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -482,10 +951,12 @@ func (d *DB) QueryGraph() (Graph, error) {
 	return results, errors.WithStack(rows.Err())
 }
 
+// tagsByNoteID returns the tags of every note that may be in the graph: those in the linked scope, which
+// includes the scope's notes and the notes they're linked to
 func (d *DB) tagsByNoteID() (map[int]string, error) {
 	tags := make(map[int]string)
 
-	rows, err := d.db.Query(sqlTags)
+	rows, err := d.query(sqlTags)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -503,7 +974,7 @@ func (d *DB) tagsByNoteID() (map[int]string, error) {
 		tags[noteID] = noteTags
 	}
 
-	return tags, nil
+	return tags, errors.WithStack(rows.Err())
 }
 
 // UniqueTags returns the leaf-node tags ([a a/b a/b/c d] -> [a/b/c d])
@@ -521,25 +992,41 @@ func rowsToResults(rows *sql.Rows) (Results, error) {
 	var id string
 	var title string
 	var tags string
+	var encrypted bool
 
 	results := make(Results, 0)
 
 	for rows.Next() {
-		err := rows.Scan(&id, &title, &tags)
+		err := rows.Scan(&id, &title, &tags, &encrypted)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 		results = append(results, &Result{
-			NoteSHA: guidToSHA(id),
-			ID:      id,
-			Title:   title,
-			Tags:    tags,
+			NoteSHA:   guidToSHA(id),
+			ID:        id,
+			Title:     title,
+			Tags:      tags,
+			Encrypted: encrypted,
 		})
 	}
 
 	return results, errors.WithStack(rows.Err())
 }
 
+// SplitTags splits a comma-separated list of tags (as returned by GROUP_CONCAT), dropping empty and
+// duplicate tags
+func SplitTags(tags string) []string {
+	split := make([]string, 0)
+
+	for _, t := range strings.Split(tags, ",") {
+		if t != "" {
+			split = append(split, t)
+		}
+	}
+
+	return util.UniqueSet(split)
+}
+
 func substringSearch(term string) string {
 	bind := strings.Builder{}
 	bind.WriteString(`%`)
@@ -548,6 +1035,11 @@ func substringSearch(term string) string {
 	return bind.String()
 }
 
+// NoteSHA returns the short hash of a note's ID that identifies its exported file
+func NoteSHA(guid string) string {
+	return guidToSHA(guid)
+}
+
 func guidToSHA(guid string) string {
 	return fmt.Sprintf("%x", md5.Sum([]byte(guid)))[0:7]
 }
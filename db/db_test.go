@@ -1,6 +1,9 @@
 package db
 
 import (
+	"database/sql"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,3 +38,262 @@ func BenchmarkQueryTitlesFuzzy(t *testing.B) {
 		assert.Nil(t, err, "error searching titles fuzzy")
 	}
 }
+
+func mustNoError(t *testing.T, err error) {
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+}
+
+// newTestDB creates a Bear 2 database, populated by the fixture statements
+func newTestDB(t *testing.T, fixture string) *DB {
+	bearDB, err := newDB(createTestDB(t, "testdata/bear2.sql", fixture))
+	mustNoError(t, err)
+	t.Cleanup(func() { bearDB.Close() })
+
+	return bearDB
+}
+
+// createTestDB creates a database from the schema file, populated by the fixture statements, and
+// returns its (read-only) data source name
+func createTestDB(t *testing.T, schemaFile, fixture string) string {
+	schema, err := os.ReadFile(schemaFile)
+	mustNoError(t, err)
+
+	dsn := filepath.Join(t.TempDir(), "database.sqlite")
+
+	conn, err := sql.Open("sqlite3", dsn)
+	mustNoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Exec(string(schema))
+	mustNoError(t, err)
+
+	_, err = conn.Exec(fixture)
+	mustNoError(t, err)
+
+	return dsn + "?mode=ro"
+}
+
+func TestQueryDeletedAttachments(t *testing.T) {
+	bearDB := newTestDB(t, `
+		INSERT INTO ZSFNOTE (Z_PK, ZUNIQUEIDENTIFIER, ZTITLE, ZTRASHED, ZTRASHEDDATE, ZARCHIVED) VALUES
+			(1, 'N-ACTIVE', 'Active', 0, NULL, 0),
+			(2, 'N-TRASHED', 'Trashed', 1, 700000000, 0),
+			(3, 'N-TRASHED-NO-DATE', 'Trashed without a date', 1, NULL, 0),
+			(4, 'N-DATED', 'Has a trashed date', 0, 700000000, 0),
+			(5, 'N-ARCHIVED', 'Archived', 0, NULL, 1);
+		INSERT INTO ZSFNOTEFILE (Z_PK, ZNOTE, ZUNIQUEIDENTIFIER, ZFILENAME) VALUES
+			(1, 1, 'F-ACTIVE', 'active.jpg'),
+			(2, 2, 'F-TRASHED', 'trashed.jpg'),
+			(3, 3, 'F-TRASHED-NO-DATE', 'no-date.pdf'),
+			(4, 4, 'F-DATED', 'dated.png'),
+			(5, 5, 'F-ARCHIVED', 'archived.jpg'),
+			(6, 99, 'F-MISSING', 'missing.jpg');
+	`)
+
+	attachments, err := bearDB.QueryDeletedAttachments()
+	mustNoError(t, err)
+
+	ids := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		ids = append(ids, a.FolderUUID+"/"+a.Filename)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"F-TRASHED/trashed.jpg",
+		"F-TRASHED-NO-DATE/no-date.pdf",
+		"F-DATED/dated.png",
+	}, ids)
+}
+
+func TestNoteStats(t *testing.T) {
+	bearDB := newTestDB(t, `
+		INSERT INTO ZSFNOTE (Z_PK, Z_OPT, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZCREATIONDATE, ZMODIFICATIONDATE, ZPINNED, ZARCHIVED, ZTRASHED, ZENCRYPTED, ZPERMANENTLYDELETED) VALUES
+			(1, 7, 'N-1', 'One', '# One', 0, 86400, 1, 0, 0, 0, 0),
+			(2, 2, 'N-2', 'Two', '# Two', 0, 0, 0, 1, 0, 1, 0),
+			(3, 1, 'N-3', 'Gone', '', 0, 0, 0, 0, 1, 0, 1);
+		INSERT INTO ZSFNOTETAG (Z_PK, ZTITLE) VALUES (1, 'work'), (2, 'work/meetings');
+		INSERT INTO Z_5TAGS (Z_5NOTES, Z_13TAGS) VALUES (1, 1), (1, 2);
+		INSERT INTO ZSFNOTEBACKLINK (Z_PK, ZLINKINGTO, ZLINKEDBY) VALUES (1, 1, 2), (2, 1, 2);
+	`)
+
+	notes, err := bearDB.NoteStats()
+	mustNoError(t, err)
+
+	assert.Len(t, notes, 2)
+	assert.Equal(t, "N-1", notes[0].ID)
+	assert.Equal(t, []string{"work", "work/meetings"}, notes[0].Tags)
+	assert.True(t, notes[0].Pinned)
+	assert.Equal(t, 7, notes[0].Saves)
+	assert.Equal(t, 2, notes[0].Links)
+	assert.True(t, notes[1].Archived)
+	assert.True(t, notes[1].Encrypted)
+	assert.Empty(t, notes[1].Tags)
+}
+
+func TestQueryTag(t *testing.T) {
+	bearDB := newTestDB(t, `
+		INSERT INTO ZSFNOTE (Z_PK, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZMODIFICATIONDATE, ZARCHIVED, ZTRASHED) VALUES
+			(1, 'N-WORK', 'Work', '#work', 0, 0, 0),
+			(2, 'N-NESTED', 'Nested', '#work/standup', 0, 0, 0),
+			(3, 'N-CASE', 'Case', '#Work/standup', 0, 0, 0),
+			(4, 'N-PREFIX', 'Prefix', '#workout', 0, 0, 0),
+			(5, 'N-WILDCARD', 'Wildcard', '#w_rk/standup', 0, 0, 0);
+		INSERT INTO ZSFNOTETAG (Z_PK, ZTITLE) VALUES
+			(1, 'work'), (2, 'work/standup'), (3, 'Work/standup'), (4, 'workout'), (5, 'w_rk/standup');
+		INSERT INTO Z_5TAGS (Z_5NOTES, Z_13TAGS) VALUES (1, 1), (2, 2), (3, 3), (4, 4), (5, 5);
+	`)
+
+	records, err := bearDB.QueryTag("work")
+	mustNoError(t, err)
+
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+
+	// both the exact and the nested tags are matched case-sensitively, and without wildcards
+	assert.Equal(t, []string{"N-WORK", "N-NESTED"}, ids)
+}
+
+func TestScope(t *testing.T) {
+	bearDB := newTestDB(t, `
+		INSERT INTO ZSFNOTE (Z_PK, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZMODIFICATIONDATE, ZPINNED, ZARCHIVED, ZTRASHED, ZPERMANENTLYDELETED) VALUES
+			(1, 'N-ACTIVE', 'Active', 'plans', 40, 0, 0, 0, 0),
+			(2, 'N-PINNED', 'Pinned', 'plans', 10, 1, 0, 0, 0),
+			(3, 'N-ARCHIVED', 'Archived', 'plans', 30, 0, 1, 0, 0),
+			(4, 'N-TRASHED', 'Trashed', 'plans', 20, 0, 0, 1, 0),
+			(5, 'N-GONE', 'Gone', 'plans', 50, 0, 0, 1, 1);
+		INSERT INTO ZSFNOTEBACKLINK (Z_PK, ZLINKINGTO, ZLINKEDBY) VALUES (1, 1, 2), (2, 3, 1), (3, 3, 4);
+	`)
+
+	ids := func(results Results) []string {
+		result := make([]string, 0, len(results))
+		for _, r := range results {
+			result = append(result, r.ID)
+		}
+		return result
+	}
+
+	tests := map[Scope][]string{
+		Active:   {"N-ACTIVE", "N-PINNED"},
+		Archived: {"N-ARCHIVED"},
+		Trashed:  {"N-TRASHED"},
+		Pinned:   {"N-PINNED"},
+		All:      {"N-ACTIVE", "N-ARCHIVED", "N-TRASHED", "N-PINNED"},
+	}
+
+	for scope, expected := range tests {
+		bearDB.SetScope(scope)
+
+		titles, err := bearDB.QueryAllTitles()
+		mustNoError(t, err)
+		assert.Equal(t, expected, ids(titles), string(scope))
+	}
+
+	// pinned notes come first, then the most recently modified
+	bearDB.SetScope(All)
+	results, err := bearDB.QueryText("plans")
+	mustNoError(t, err)
+	assert.Equal(t, []string{"N-PINNED", "N-ACTIVE", "N-ARCHIVED", "N-TRASHED"}, ids(results))
+
+	// links from the notes in scope, to active notes for the active scope and to any note otherwise
+	graph, err := bearDB.QueryGraph()
+	mustNoError(t, err)
+	assert.Len(t, graph, 3)
+
+	bearDB.SetScope(Active)
+	graph, err = bearDB.QueryGraph()
+	mustNoError(t, err)
+	assert.Len(t, graph, 1)
+	assert.Equal(t, "N-ACTIVE", graph[0].Source.ID)
+
+	bearDB.SetScope(Archived)
+	graph, err = bearDB.QueryGraph()
+	mustNoError(t, err)
+	assert.Len(t, graph, 2)
+	for _, link := range graph {
+		assert.Equal(t, "N-ARCHIVED", link.Source.ID)
+	}
+}
+
+func TestGraphTargetTags(t *testing.T) {
+	bearDB := newTestDB(t, `
+		INSERT INTO ZSFNOTE (Z_PK, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZARCHIVED, ZTRASHED) VALUES
+			(1, 'N-ARCHIVED', 'Archived', '[[Log]]', 1, 0),
+			(2, 'N-ACTIVE', 'Log', '#captainslog', 0, 0);
+		INSERT INTO ZSFNOTETAG (Z_PK, ZTITLE) VALUES (1, 'captainslog');
+		INSERT INTO Z_5TAGS (Z_5NOTES, Z_13TAGS) VALUES (2, 1);
+		INSERT INTO ZSFNOTEBACKLINK (Z_PK, ZLINKINGTO, ZLINKEDBY) VALUES (1, 1, 2);
+	`)
+	bearDB.SetScope(Archived)
+
+	graph, err := bearDB.QueryGraph()
+	mustNoError(t, err)
+
+	// the active target is outside the scope, but its tags are still loaded, so it can be excluded
+	assert.Len(t, graph, 1)
+	assert.Equal(t, "N-ARCHIVED", graph[0].Source.ID)
+	assert.Equal(t, "N-ACTIVE", graph[0].Target.ID)
+	assert.Equal(t, "captainslog", graph[0].Target.Tags)
+}
+
+func TestParseScope(t *testing.T) {
+	scope, err := ParseScope("Archived")
+	assert.NoError(t, err)
+	assert.Equal(t, Archived, scope)
+
+	_, err = ParseScope("deleted")
+	assert.EqualError(t, err, "unknown scope: deleted")
+
+	assert.Equal(t, "active, archived, trashed, pinned or all", ScopeNames())
+}
+
+func TestEncryptedNotes(t *testing.T) {
+	bearDB := newTestDB(t, `
+		INSERT INTO ZSFNOTE (Z_PK, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZMODIFICATIONDATE, ZARCHIVED, ZTRASHED, ZENCRYPTED, ZLOCKED, ZENCRYPTEDDATA) VALUES
+			(1, 'N-PLAIN', 'Plain', 'secret plans', 30, 0, 0, 0, 0, NULL),
+			(2, 'N-ENCRYPTED', 'Encrypted', 'secret plans', 20, 0, 0, 1, 0, X'DEADBEEF'),
+			(3, 'N-LOCKED', 'Locked', 'secret plans', 10, 0, 0, NULL, 1, NULL);
+		INSERT INTO ZSFNOTEFILE (Z_PK, ZNOTE, ZUNIQUEIDENTIFIER, ZFILENAME, ZSEARCHTEXT) VALUES
+			(1, 1, 'F-PLAIN', 'plain.jpg', 'secret scan'),
+			(2, 2, 'F-ENCRYPTED', 'encrypted.jpg', 'secret scan');
+	`)
+
+	records, err := bearDB.Records()
+	mustNoError(t, err)
+	assert.Len(t, records, 3)
+	for _, r := range records {
+		assert.Equal(t, r.ID != "N-PLAIN", r.Encrypted, r.ID)
+		assert.Equal(t, r.ID == "N-PLAIN", r.Text != "", r.ID)
+	}
+
+	// encrypted notes' text never matches, but their titles do
+	results, err := bearDB.QueryText("secret")
+	mustNoError(t, err)
+	assert.Len(t, results, 1)
+	assert.False(t, results[0].Encrypted)
+
+	results, err = bearDB.QueryTitles("locked", false)
+	mustNoError(t, err)
+	assert.Len(t, results, 1)
+	assert.True(t, results[0].Encrypted)
+
+	matches, err := bearDB.QueryAttachmentText("secret")
+	mustNoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "N-PLAIN", matches[0].Note.ID)
+
+	notes, err := bearDB.NoteStats()
+	mustNoError(t, err)
+	for _, n := range notes {
+		assert.Equal(t, n.ID != "N-PLAIN", n.Encrypted, n.ID)
+	}
+
+	bearDB.SetExcludeEncrypted(true)
+	titles, err := bearDB.QueryAllTitles()
+	mustNoError(t, err)
+	assert.Len(t, titles, 1)
+	assert.Equal(t, "N-PLAIN", titles[0].ID)
+}
@@ -73,6 +73,16 @@ func (e *Exporter) Archive(records []*db.Record, trashDirectory string) error {
 	return nil
 }
 
+// Path returns the path of the previously-exported file for the note with the SHA, if there is one
+func (e *Exporter) Path(sha string) (string, bool) {
+	filename, ok := e.mapping[SHA(sha)]
+	if !ok {
+		return "", false
+	}
+
+	return path.Join(e.directory, string(filename)), true
+}
+
 // Returns true if the SHA and its new data differs from the previously-exported contents
 func (e *Exporter) IsChanged(record *db.Record) (bool, error) {
 	filename, ok := e.mapping[SHA(record.SHA)]
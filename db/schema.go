@@ -0,0 +1,260 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	noteEntityName = "SFNote"
+	tagEntityName  = "SFNoteTag"
+
+	sqlEntities = `
+		SELECT Z_ENT, Z_NAME
+		FROM Z_PRIMARYKEY
+		WHERE Z_NAME IN ('SFNote', 'SFNoteTag')
+	`
+
+	sqlModelVersion = `
+		SELECT COALESCE(MAX(Z_VERSION), 0)
+		FROM Z_METADATA
+	`
+
+	sqlColumns = `
+		SELECT name
+		FROM pragma_table_info(?)
+	`
+)
+
+// queries are written against Bear 2's schema, whose entity numbers appear in the names of the join
+// table between notes and tags
+const (
+	referenceTagsTable  = "Z_5TAGS"
+	referenceNoteColumn = "Z_5NOTES"
+	referenceTagColumn  = "Z_13TAGS"
+)
+
+// releases names the Bear releases whose schemas are known, by their note and tag entity numbers
+var releases = map[[2]int]string{
+	{7, 14}: "Bear 1.x",
+	{5, 13}: "Bear 2.x",
+}
+
+// requiredColumns are the core tables and columns that queries can't do without, besides the join table
+var requiredColumns = map[string][]string{
+	"ZSFNOTE":         {"Z_PK", "Z_OPT", "ZUNIQUEIDENTIFIER", "ZTITLE", "ZTEXT", "ZCREATIONDATE", "ZMODIFICATIONDATE", "ZARCHIVED", "ZTRASHED", "ZPINNED", "ZPERMANENTLYDELETED"},
+	"ZSFNOTETAG":      {"Z_PK", "ZTITLE"},
+	"ZSFNOTEFILE":     {"Z_PK", "ZNOTE", "ZUNIQUEIDENTIFIER", "ZFILENAME"},
+	"ZSFNOTEBACKLINK": {"ZLINKINGTO", "ZLINKEDBY"},
+}
+
+// optionalColumns are the other columns that queries use, which not every Bear release has, along with
+// the value that's used in place of each one that's missing: e.g. notes are neither encrypted (Bear 2)
+// nor locked (Bear 1) if they can't be, and every note is searched for todos if Bear doesn't count them
+var optionalColumns = map[string]map[string]string{
+	"ZSFNOTE": {
+		"ZENCRYPTED":       "0",
+		"ZLOCKED":          "0",
+		"ZTODOINCOMPLETED": "1",
+		"ZTRASHEDDATE":     "NULL",
+	},
+	"ZSFNOTEFILE": {
+		"ZSEARCHTEXT":              "NULL",
+		"ZFILESIZE":                "NULL",
+		"ZWIDTH":                   "NULL",
+		"ZHEIGHT":                  "NULL",
+		"ZWIDTH1":                  "NULL",
+		"ZHEIGHT1":                 "NULL",
+		"ZNORMALIZEDFILEEXTENSION": "NULL",
+		"ZCREATIONDATE":            "NULL",
+		"ZINSERTIONDATE":           "NULL",
+		"ZINDEX":                   "NULL",
+		"ZDOWNLOADED":              "1",
+		"ZUPLOADED":                "1",
+		"ZPERMANENTLYDELETED":      "0",
+	},
+}
+
+// tableAliasRegex matches a table and its alias in a query's FROM or JOIN clause, e.g. `ZSFNOTEFILE f`
+var tableAliasRegex = regexp.MustCompile(`\b(ZSF\w+)\s+(\w+)`)
+
+// Schema describes the Core Data schema of a Bear database. Core Data numbers each entity, and names
+// the join table between notes and tags after those numbers (e.g. Z_5TAGS, joining Z_5NOTES to
+// Z_13TAGS), so the names differ between Bear releases.
+type Schema struct {
+	// ModelVersion is Core Data's metadata version, from Z_METADATA
+	ModelVersion int
+	NoteEntity   int
+	TagEntity    int
+	// Missing are the optional columns that the database doesn't have, by table, with the values used
+	// in their place
+	Missing map[string]map[string]string
+}
+
+// Release is the name of the Bear release that uses the schema, if it's known
+func (s *Schema) Release() string {
+	if release, ok := releases[[2]int{s.NoteEntity, s.TagEntity}]; ok {
+		return release
+	}
+	return "unknown Bear release"
+}
+
+func (s *Schema) String() string {
+	if s.NoteEntity == 0 || s.TagEntity == 0 {
+		return "not a recognizable Bear database"
+	}
+
+	return fmt.Sprintf("%s, model version %d, %s entity %d, %s entity %d",
+		s.Release(), s.ModelVersion, noteEntityName, s.NoteEntity, tagEntityName, s.TagEntity)
+}
+
+// TagsTable is the join table between notes and tags
+func (s *Schema) TagsTable() string {
+	return fmt.Sprintf("Z_%dTAGS", s.NoteEntity)
+}
+
+// NoteColumn is the join table's reference to a note
+func (s *Schema) NoteColumn() string {
+	return fmt.Sprintf("Z_%dNOTES", s.NoteEntity)
+}
+
+// TagColumn is the join table's reference to a tag
+func (s *Schema) TagColumn() string {
+	return fmt.Sprintf("Z_%dTAGS", s.TagEntity)
+}
+
+// rewrite replaces the reference schema's join table and column names in the query with the schema's,
+// and references to missing optional columns with the values used in their place
+func (s *Schema) rewrite(query string) string {
+	query = strings.NewReplacer(
+		referenceTagsTable, s.TagsTable(),
+		referenceNoteColumn, s.NoteColumn(),
+		referenceTagColumn, s.TagColumn(),
+	).Replace(query)
+
+	if len(s.Missing) == 0 {
+		return query
+	}
+
+	for _, match := range tableAliasRegex.FindAllStringSubmatch(query, -1) {
+		for column, value := range s.Missing[match[1]] {
+			reference := regexp.MustCompile(`\b` + match[2] + `\.` + column + `\b`)
+			query = reference.ReplaceAllLiteralString(query, value)
+		}
+	}
+
+	return query
+}
+
+// detectSchema reads the entity numbers from Z_PRIMARYKEY, and checks that the tables and columns that
+// queries depend on exist, returning an error naming the schema if they don't. Missing optional columns
+// are recorded, to be replaced in queries.
+func detectSchema(db *sql.DB) (*Schema, error) {
+	schema := &Schema{}
+
+	if err := db.QueryRow(sqlModelVersion).Scan(&schema.ModelVersion); err != nil {
+		return nil, unsupported(schema, "no Core Data metadata (Z_METADATA): %s", err)
+	}
+
+	rows, err := db.Query(sqlEntities)
+	if err != nil {
+		return nil, unsupported(schema, "no Core Data entities (Z_PRIMARYKEY): %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entity int
+		var name string
+
+		if err := rows.Scan(&entity, &name); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		switch name {
+		case noteEntityName:
+			schema.NoteEntity = entity
+		case tagEntityName:
+			schema.TagEntity = entity
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if schema.NoteEntity == 0 || schema.TagEntity == 0 {
+		return nil, unsupported(schema, "no %s and %s entities in Z_PRIMARYKEY", noteEntityName, tagEntityName)
+	}
+
+	tables := map[string][]string{
+		schema.TagsTable(): {schema.NoteColumn(), schema.TagColumn()},
+	}
+	names := []string{schema.TagsTable()}
+
+	for table, columns := range requiredColumns {
+		tables[table] = columns
+		names = append(names, table)
+	}
+	sort.Strings(names[1:])
+
+	for _, table := range names {
+		existing, err := tableColumns(db, table)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if len(existing) == 0 {
+			return nil, unsupported(schema, "no table %s", table)
+		}
+
+		for _, column := range tables[table] {
+			if !existing[column] {
+				return nil, unsupported(schema, "no column %s.%s", table, column)
+			}
+		}
+
+		for column, value := range optionalColumns[table] {
+			if existing[column] {
+				continue
+			}
+			if schema.Missing == nil {
+				schema.Missing = make(map[string]map[string]string)
+			}
+			if schema.Missing[table] == nil {
+				schema.Missing[table] = make(map[string]string)
+			}
+			schema.Missing[table][column] = value
+		}
+	}
+
+	return schema, nil
+}
+
+// tableColumns returns the names of the table's columns, which are empty if there's no such table
+func tableColumns(db *sql.DB, table string) (map[string]bool, error) {
+	rows, err := db.Query(sqlColumns, table)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	var column string
+
+	for rows.Next() {
+		if err := rows.Scan(&column); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		columns[column] = true
+	}
+
+	return columns, errors.WithStack(rows.Err())
+}
+
+func unsupported(schema *Schema, format string, args ...interface{}) error {
+	return fmt.Errorf("unsupported Bear database schema (%s): %s", schema, fmt.Sprintf(format, args...))
+}
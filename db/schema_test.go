@@ -0,0 +1,122 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const schemaFixture = `
+	INSERT INTO ZSFNOTE (Z_PK, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZMODIFICATIONDATE, ZARCHIVED, ZTRASHED) VALUES
+		(1, 'N-1', 'One', '# One', 0, 0, 0);
+	INSERT INTO ZSFNOTETAG (Z_PK, ZTITLE) VALUES (1, 'work'), (2, 'work/meetings');
+`
+
+func TestDetectSchema(t *testing.T) {
+	tests := map[string]struct {
+		release string
+		join    string
+	}{
+		"testdata/bear1.sql": {"Bear 1.x", "INSERT INTO Z_7TAGS (Z_7NOTES, Z_14TAGS) VALUES (1, 1), (1, 2);"},
+		"testdata/bear2.sql": {"Bear 2.x", "INSERT INTO Z_5TAGS (Z_5NOTES, Z_13TAGS) VALUES (1, 1), (1, 2);"},
+	}
+
+	for schemaFile, test := range tests {
+		bearDB, err := newDB(createTestDB(t, schemaFile, schemaFixture+test.join))
+		mustNoError(t, err)
+		defer bearDB.Close()
+
+		assert.Equal(t, test.release, bearDB.Schema().Release(), schemaFile)
+
+		tags, err := bearDB.QueryTags()
+		assert.NoError(t, err, schemaFile)
+		assert.Equal(t, []string{"work/meetings"}, tags, schemaFile)
+
+		records, err := bearDB.Records()
+		assert.NoError(t, err, schemaFile)
+		assert.Equal(t, "work,work/meetings", records[0].Tags, schemaFile)
+	}
+}
+
+func TestMissingOptionalColumns(t *testing.T) {
+	assert.Empty(t, newTestDB(t, "").Schema().Missing, "Bear 2 has every optional column")
+
+	bearDB, err := newDB(createTestDB(t, "testdata/bear1.sql", `
+		INSERT INTO ZSFNOTE (Z_PK, Z_OPT, ZUNIQUEIDENTIFIER, ZTITLE, ZTEXT, ZCREATIONDATE, ZMODIFICATIONDATE, ZARCHIVED, ZTRASHED, ZPINNED, ZLOCKED) VALUES
+			(1, 3, 'N-1', 'One', '- [ ] call bob', 0, 0, 0, 0, 0, 0),
+			(2, 1, 'N-2', 'Two', 'secret', 0, 0, 0, 0, 0, 1);
+		INSERT INTO ZSFNOTEFILE (Z_PK, ZNOTE, ZUNIQUEIDENTIFIER, ZFILENAME) VALUES (1, 1, 'F-1', 'scan.pdf');
+		INSERT INTO ZSFNOTEBACKLINK (Z_PK, ZLINKINGTO, ZLINKEDBY) VALUES (1, 1, 2);
+	`))
+	mustNoError(t, err)
+	defer bearDB.Close()
+
+	missing := bearDB.Schema().Missing
+	assert.Equal(t, "0", missing["ZSFNOTE"]["ZENCRYPTED"])
+	assert.NotContains(t, missing["ZSFNOTE"], "ZLOCKED")
+	assert.Contains(t, missing["ZSFNOTEFILE"], "ZSEARCHTEXT")
+
+	// Bear doesn't count the todos, so every note is searched for them
+	todos, err := bearDB.QueryOpenTodos()
+	assert.NoError(t, err)
+	assert.Len(t, todos, 2)
+
+	inventory, err := bearDB.AttachmentInventory()
+	assert.NoError(t, err)
+	assert.Len(t, inventory, 1)
+	assert.Equal(t, int64(0), inventory[0].Size)
+	assert.True(t, inventory[0].Downloaded)
+
+	matches, err := bearDB.QueryAttachmentText("scan")
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+
+	stats, err := bearDB.NoteStats()
+	assert.NoError(t, err)
+	assert.Len(t, stats, 2)
+	assert.Equal(t, 3, stats[0].Saves)
+	assert.True(t, stats[1].Encrypted, "locked notes are encrypted")
+
+	graph, err := bearDB.QueryGraph()
+	assert.NoError(t, err)
+	assert.Len(t, graph, 1)
+
+	_, err = bearDB.QueryDeletedAttachments()
+	assert.NoError(t, err)
+}
+
+func TestDetectUnsupportedSchema(t *testing.T) {
+	// without any Core Data entities
+	_, err := newDB(createTestDB(t, "../schema.sql", ""))
+	assert.EqualError(t, err, "unsupported Bear database schema (not a recognizable Bear database): no SFNote and SFNoteTag entities in Z_PRIMARYKEY")
+
+	// with entities whose join table is missing
+	_, err = newDB(createTestDB(t, "../schema.sql", `
+		INSERT INTO Z_PRIMARYKEY (Z_ENT, Z_NAME, Z_SUPER, Z_MAX) VALUES (9, 'SFNote', 0, 0), (21, 'SFNoteTag', 0, 0);
+		INSERT INTO Z_METADATA (Z_VERSION) VALUES (3);
+	`))
+	assert.EqualError(t, err, "unsupported Bear database schema (unknown Bear release, model version 3, SFNote entity 9, SFNoteTag entity 21): no table Z_9TAGS")
+
+	// without Core Data's tables at all
+	_, err = newDB(createTestDB(t, "testdata/bear2.sql", "DROP TABLE Z_METADATA;"))
+	assert.ErrorContains(t, err, "unsupported Bear database schema (not a recognizable Bear database): no Core Data metadata (Z_METADATA): no such table: Z_METADATA")
+
+	// with a core column missing
+	_, err = newDB(createTestDB(t, "testdata/bear2.sql", "ALTER TABLE ZSFNOTETAG DROP COLUMN ZTITLE;"))
+	assert.EqualError(t, err, "unsupported Bear database schema (Bear 2.x, model version 1, SFNote entity 5, SFNoteTag entity 13): no column ZSFNOTETAG.ZTITLE")
+}
+
+func TestSchemaRewrite(t *testing.T) {
+	schema := &Schema{NoteEntity: 7, TagEntity: 14}
+
+	assert.Equal(t,
+		"JOIN Z_7TAGS tags ON note.Z_PK = tags.Z_7NOTES JOIN ZSFNOTETAG tag ON tags.Z_14TAGS = tag.Z_PK",
+		schema.rewrite("JOIN Z_5TAGS tags ON note.Z_PK = tags.Z_5NOTES JOIN ZSFNOTETAG tag ON tags.Z_13TAGS = tag.Z_PK"))
+
+	// missing columns are replaced for their own table, but not for others with the same column
+	schema.Missing = map[string]map[string]string{"ZSFNOTEFILE": {"ZCREATIONDATE": "NULL"}}
+
+	assert.Equal(t,
+		"SELECT n.ZCREATIONDATE, NULL FROM ZSFNOTEFILE f JOIN ZSFNOTE n ON n.Z_PK = f.ZNOTE",
+		schema.rewrite("SELECT n.ZCREATIONDATE, f.ZCREATIONDATE FROM ZSFNOTEFILE f JOIN ZSFNOTE n ON n.Z_PK = f.ZNOTE"))
+}
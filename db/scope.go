@@ -0,0 +1,110 @@
+package db
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scope selects notes by their state in Bear: active, archived, trashed, pinned or all of them
+type Scope string
+
+const (
+	// Active notes are those neither archived nor trashed
+	Active   Scope = "active"
+	Archived Scope = "archived"
+	Trashed  Scope = "trashed"
+	// Pinned notes are the active notes that are pinned
+	Pinned Scope = "pinned"
+	// All notes includes archived and trashed notes, but not those permanently deleted
+	All Scope = "all"
+)
+
+// Scopes is every scope, in the order they're listed in help text
+var Scopes = []Scope{Active, Archived, Trashed, Pinned, All}
+
+// placeholderRegex matches the `{{scope alias}}`, `{{linked alias}}`, `{{encrypted alias}}` and `{{text alias}}`
+// placeholders in queries
+var placeholderRegex = regexp.MustCompile(`\{\{(scope|linked|encrypted|text) (\w+)\}\}`)
+
+// ParseScope returns the named scope
+func ParseScope(name string) (Scope, error) {
+	for _, scope := range Scopes {
+		if string(scope) == strings.ToLower(name) {
+			return scope, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown scope: %s", name)
+}
+
+// ScopeNames returns the names of every scope, for use in help text (e.g. `active, archived, ... or all`)
+func ScopeNames() string {
+	names := make([]string, 0, len(Scopes))
+	for _, scope := range Scopes {
+		names = append(names, string(scope))
+	}
+
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// condition returns the SQL condition selecting the scope's notes from the ZSFNOTE table aliased as alias
+func (s Scope) condition(alias string) string {
+	switch s {
+	case Archived:
+		return fmt.Sprintf("%[1]s.ZARCHIVED = 1 AND %[1]s.ZTRASHED = 0", alias)
+	case Trashed:
+		return fmt.Sprintf("%[1]s.ZTRASHED = 1 AND COALESCE(%[1]s.ZPERMANENTLYDELETED, 0) = 0", alias)
+	case Pinned:
+		return fmt.Sprintf("%[1]s.ZPINNED = 1 AND %[1]s.ZARCHIVED = 0 AND %[1]s.ZTRASHED = 0", alias)
+	case All:
+		return fmt.Sprintf("COALESCE(%s.ZPERMANENTLYDELETED, 0) = 0", alias)
+	default:
+		return fmt.Sprintf("%[1]s.ZARCHIVED = 0 AND %[1]s.ZTRASHED = 0", alias)
+	}
+}
+
+// linked is the scope of the notes that the scope's notes are linked to in a graph: other active notes
+// for active and pinned notes, and any note for the others (e.g. an archived note's links to active notes)
+func (s Scope) linked() Scope {
+	switch s {
+	case Active, Pinned:
+		return Active
+	}
+
+	return All
+}
+
+// encrypted returns the SQL expression that's true if the note aliased as alias is encrypted (Bear 2) or
+// locked (Bear 1)
+func encrypted(alias string) string {
+	return fmt.Sprintf("(COALESCE(%[1]s.ZENCRYPTED, 0) = 1 OR COALESCE(%[1]s.ZLOCKED, 0) = 1)", alias)
+}
+
+// expand replaces the placeholders in the query: `{{scope alias}}` with the scope's condition (also
+// excluding encrypted notes, if excludeEncrypted), `{{linked alias}}` likewise with the linked scope's
+// condition, `{{encrypted alias}}` with whether the note is encrypted, and `{{text alias}}` with the
+// note's text, which is empty for encrypted notes
+func (s Scope) expand(query string, excludeEncrypted bool) string {
+	return placeholderRegex.ReplaceAllStringFunc(query, func(match string) string {
+		groups := placeholderRegex.FindStringSubmatch(match)
+		alias := groups[2]
+		scope := s
+
+		switch groups[1] {
+		case "encrypted":
+			return encrypted(alias)
+		case "text":
+			return fmt.Sprintf("CASE WHEN %s THEN '' ELSE %s.ZTEXT END", encrypted(alias), alias)
+		case "linked":
+			scope = s.linked()
+		}
+
+		condition := scope.condition(alias)
+		if excludeEncrypted {
+			condition += " AND NOT " + encrypted(alias)
+		}
+
+		return "(" + condition + ")"
+	})
+}
@@ -0,0 +1,18 @@
+-- Bear 1.x: notes are entity 7 and tags entity 14, so they're joined by Z_7TAGS. This is a reduced schema,
+-- written by hand rather than dumped from a Bear 1 database: it has only the tables and columns that
+-- queries require, plus ZLOCKED for Bear 1's locked notes, so it lacks every other optional column (note
+-- encryption and todo counts, and attachments' search text, dimensions and sync state).
+CREATE TABLE ZSFNOTE ( Z_PK INTEGER PRIMARY KEY, Z_ENT INTEGER, Z_OPT INTEGER, ZARCHIVED INTEGER, ZLOCKED INTEGER, ZPERMANENTLYDELETED INTEGER, ZPINNED INTEGER, ZTRASHED INTEGER, ZCREATIONDATE TIMESTAMP, ZMODIFICATIONDATE TIMESTAMP, ZTEXT VARCHAR, ZTITLE VARCHAR, ZUNIQUEIDENTIFIER VARCHAR );
+CREATE TABLE Z_7TAGS ( Z_7NOTES INTEGER, Z_14TAGS INTEGER, PRIMARY KEY (Z_7NOTES, Z_14TAGS) );
+CREATE TABLE ZSFNOTEBACKLINK ( Z_PK INTEGER PRIMARY KEY, Z_ENT INTEGER, Z_OPT INTEGER, ZLINKEDBY INTEGER, ZLINKINGTO INTEGER );
+CREATE TABLE ZSFNOTEFILE ( Z_PK INTEGER PRIMARY KEY, Z_ENT INTEGER, Z_OPT INTEGER, ZNOTE INTEGER, ZFILENAME VARCHAR, ZUNIQUEIDENTIFIER VARCHAR );
+CREATE TABLE ZSFNOTETAG ( Z_PK INTEGER PRIMARY KEY, Z_ENT INTEGER, Z_OPT INTEGER, ZTITLE VARCHAR );
+CREATE INDEX Z_7TAGS_Z_14TAGS_INDEX ON Z_7TAGS (Z_14TAGS, Z_7NOTES);
+CREATE INDEX ZSFNOTEBACKLINK_ZLINKEDBY_INDEX ON ZSFNOTEBACKLINK (ZLINKEDBY);
+CREATE INDEX ZSFNOTEBACKLINK_ZLINKINGTO_INDEX ON ZSFNOTEBACKLINK (ZLINKINGTO);
+CREATE INDEX ZSFNOTEFILE_ZNOTE_INDEX ON ZSFNOTEFILE (ZNOTE);
+CREATE TABLE Z_PRIMARYKEY (Z_ENT INTEGER PRIMARY KEY, Z_NAME VARCHAR, Z_SUPER INTEGER, Z_MAX INTEGER);
+CREATE TABLE Z_METADATA (Z_VERSION INTEGER PRIMARY KEY, Z_UUID VARCHAR(255), Z_PLIST BLOB);
+CREATE TABLE Z_MODELCACHE (Z_CONTENT BLOB);
+INSERT INTO Z_PRIMARYKEY (Z_ENT, Z_NAME, Z_SUPER, Z_MAX) VALUES (7, 'SFNote', 0, 0), (14, 'SFNoteTag', 0, 0);
+INSERT INTO Z_METADATA (Z_VERSION, Z_UUID) VALUES (1, 'B1B1B1B1-0000-0000-0000-000000000001');
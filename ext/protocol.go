@@ -0,0 +1,136 @@
+package ext
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version is the current version of the x-fb payload encoding
+const Version = 1
+
+// versionedRegex matches a versioned payload, v<version>.<base64url-encoded JSON>, capturing the version
+// and the encoded JSON
+var versionedRegex = regexp.MustCompile(`^v(\d+)\.([A-Za-z0-9_-]+)$`)
+
+// Action is what an x-fb argument asks freddiebear to do
+type Action string
+
+const (
+	Create   Action = "create"
+	Open     Action = "open"
+	Append   Action = "append"
+	CopyLink Action = "copy-link"
+	Reveal   Action = "reveal"
+)
+
+var actions = map[Action]bool{Create: true, Open: true, Append: true, CopyLink: true, Reveal: true}
+
+// Payload is the data an x-fb argument carries
+type Payload struct {
+	ID     string   `json:"id,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Header string   `json:"header,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// Arg is a parsed `x-fb-<action>:<payload>` argument
+type Arg struct {
+	// Version is the payload's encoding version: 0 for a legacy, unencoded payload
+	Version int
+	Action  Action
+	Payload Payload
+}
+
+// NewArg creates a current-version argument
+func NewArg(action Action, payload Payload) *Arg {
+	return &Arg{Version: Version, Action: action, Payload: payload}
+}
+
+// String encodes the argument as `x-fb-<action>:v1.<payload>`, where the payload is base64url-encoded
+// JSON, so that it can safely contain any characters. Legacy (version 0) arguments are encoded as
+// `x-fb-<action>:<title or id>`.
+func (a *Arg) String() string {
+	if a.Version == 0 {
+		value := a.Payload.Title
+		if value == "" {
+			value = a.Payload.ID
+		}
+		return CreateKeyValue(string(a.Action), value)
+	}
+
+	// a Payload always marshals
+	data, _ := json.Marshal(a.Payload)
+
+	return CreateKeyValue(string(a.Action), fmt.Sprintf("v%d.%s", a.Version, base64.RawURLEncoding.EncodeToString(data)))
+}
+
+// IsArg returns true if s looks like an x-fb argument
+func IsArg(s string) bool {
+	return strings.HasPrefix(s, X_FREDDIEBEAR+"-")
+}
+
+// ParseArg parses an x-fb argument. A payload that looks like v<version>.<base64url> is versioned, and
+// an error if its version is unknown. Other payloads, along with v1 payloads that aren't encoded JSON
+// objects (e.g. a legacy title such as `v1.2`), are legacy, unencoded payloads: a title for create and
+// an ID for the other actions.
+func ParseArg(s string) (*Arg, error) {
+	if !IsArg(s) {
+		return nil, errors.Errorf("not an %s argument: %s", X_FREDDIEBEAR, s)
+	}
+
+	rest := strings.TrimPrefix(s, X_FREDDIEBEAR+"-")
+
+	i := strings.Index(rest, ":")
+	if i < 0 {
+		return nil, errors.Errorf("missing payload: %s", s)
+	}
+
+	arg := &Arg{Action: Action(rest[:i])}
+	payload := rest[i+1:]
+
+	if !actions[arg.Action] {
+		return nil, errors.Errorf("unknown action: %s", arg.Action)
+	}
+
+	if groups := versionedRegex.FindStringSubmatch(payload); groups != nil {
+		version, err := strconv.Atoi(groups[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse payload version: %s", s)
+		}
+
+		switch version {
+		case 1:
+			if decodeV1(groups[2], &arg.Payload) {
+				arg.Version = version
+				return arg, nil
+			}
+		default:
+			return nil, errors.Errorf("unsupported payload version %d: %s", version, s)
+		}
+	}
+
+	if arg.Action == Create {
+		arg.Payload.Title = payload
+	} else {
+		arg.Payload.ID = payload
+	}
+
+	return arg, nil
+}
+
+// decodeV1 decodes a version 1 payload, base64url-encoded JSON, returning false if it isn't one
+func decodeV1(encoded string, payload *Payload) bool {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || !strings.HasPrefix(string(data), "{") {
+		return false
+	}
+
+	return json.Unmarshal(data, payload) == nil
+}
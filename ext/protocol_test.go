@@ -0,0 +1,63 @@
+package ext
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgRoundTrip(t *testing.T) {
+	args := []*Arg{
+		NewArg(Create, Payload{Title: "a: b, c & d", Text: "line 1\nline 2", Tags: []string{"work", "x/y"}}),
+		NewArg(Open, Payload{ID: "ABC-123", Header: "Heading #1"}),
+		NewArg(Append, Payload{Title: "Inbox", Text: "x-fb-create:nested?"}),
+		NewArg(CopyLink, Payload{ID: "ABC-123", Title: "Note"}),
+		NewArg(Reveal, Payload{ID: "ABC-123"}),
+	}
+
+	for _, arg := range args {
+		s := arg.String()
+		assert.True(t, strings.HasPrefix(s, "x-fb-"+string(arg.Action)+":v1."), s)
+		assert.NotContains(t, s[len("x-fb-"):], "\n")
+
+		parsed, err := ParseArg(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, arg, parsed)
+	}
+}
+
+func TestParseLegacyArg(t *testing.T) {
+	arg, err := ParseArg(CreateKeyValue("create", "My Note: v1"))
+	assert.NoError(t, err)
+	assert.Equal(t, &Arg{Version: 0, Action: Create, Payload: Payload{Title: "My Note: v1"}}, arg)
+	assert.Equal(t, "x-fb-create:My Note: v1", arg.String())
+
+	arg, err = ParseArg("x-fb-open:ABC-123")
+	assert.NoError(t, err)
+	assert.Equal(t, &Arg{Version: 0, Action: Open, Payload: Payload{ID: "ABC-123"}}, arg)
+	assert.Equal(t, "x-fb-open:ABC-123", arg.String())
+
+	// legacy payloads that start like a v1 payload, but aren't encoded JSON
+	for _, title := range []string{"v1.2", "v1.2 release notes", "v1.!!!", "v1.bm90IGpzb24"} {
+		arg, err = ParseArg(CreateKeyValue("create", title))
+		assert.NoError(t, err, title)
+		assert.Equal(t, &Arg{Version: 0, Action: Create, Payload: Payload{Title: title}}, arg)
+	}
+}
+
+func TestParseArgErrors(t *testing.T) {
+	for _, s := range []string{
+		"ABC-123",
+		"x-fb-create",
+		"x-fb-delete:ABC-123",
+		"x-fb-open:v0.e30",
+		"x-fb-open:v2.eyJpZCI6IkFCQy0xMjMifQ",
+	} {
+		_, err := ParseArg(s)
+		assert.Error(t, err, s)
+	}
+
+	_, err := ParseArg("x-fb-open:v2.e30")
+	assert.EqualError(t, err, "unsupported payload version 2: x-fb-open:v2.e30")
+}
@@ -3,15 +3,23 @@ package main
 import (
 	"log"
 
+	"github.com/mnadel/freddiebear/cmd/attachments"
 	"github.com/mnadel/freddiebear/cmd/backlinks"
+	"github.com/mnadel/freddiebear/cmd/capture"
 	"github.com/mnadel/freddiebear/cmd/cleanup"
+	"github.com/mnadel/freddiebear/cmd/dispatch"
+	"github.com/mnadel/freddiebear/cmd/dupes"
 	"github.com/mnadel/freddiebear/cmd/export"
 	"github.com/mnadel/freddiebear/cmd/forwardlinks"
 	"github.com/mnadel/freddiebear/cmd/graph"
 	"github.com/mnadel/freddiebear/cmd/journal"
+	"github.com/mnadel/freddiebear/cmd/recent"
+	"github.com/mnadel/freddiebear/cmd/related"
 	"github.com/mnadel/freddiebear/cmd/search"
+	"github.com/mnadel/freddiebear/cmd/stats"
 	"github.com/mnadel/freddiebear/cmd/tags"
 	"github.com/mnadel/freddiebear/cmd/titles"
+	"github.com/mnadel/freddiebear/cmd/todos"
 	"github.com/mnadel/freddiebear/cmd/transcript"
 	"github.com/mnadel/freddiebear/cmd/version"
 	"github.com/spf13/cobra"
@@ -34,7 +42,15 @@ func main() {
 	cmd.AddCommand(transcript.New())
 	cmd.AddCommand(tags.New())
 	cmd.AddCommand(cleanup.New())
+	cmd.AddCommand(capture.New())
+	cmd.AddCommand(dispatch.New())
 	cmd.AddCommand(titles.New())
+	cmd.AddCommand(todos.New())
+	cmd.AddCommand(attachments.New())
+	cmd.AddCommand(dupes.New())
+	cmd.AddCommand(related.New())
+	cmd.AddCommand(stats.New())
+	cmd.AddCommand(recent.New())
 
 	if err := cmd.Execute(); err != nil {
 		log.Fatal(err)
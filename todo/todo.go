@@ -0,0 +1,165 @@
+package todo
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	incompleteMarker = []byte("[ ]")
+	completeMarkers  = [][]byte{[]byte("[x]"), []byte("[X]")}
+
+	// dueRegex matches inline due dates, e.g. `@due(2024-05-01)` or `📅 2024-05-01`
+	dueRegex = regexp.MustCompile(`(?:@due\(\s*|📅\s*)(\d{4}-\d{2}-\d{2})`)
+)
+
+const dueFormat = "2006-01-02"
+
+// Item is a Markdown task list item, e.g. `- [ ] buy milk`
+type Item struct {
+	// Text is the item's raw Markdown, without the list and checkbox markers
+	Text string
+	Done bool
+	// Heading is the text of the nearest heading above the item, if any
+	Heading string
+	// Due is the item's inline due date, or the zero time if it doesn't have one
+	Due time.Time
+}
+
+// Parse returns all task list items in the Markdown source, in document order. When bearSyntax is
+// true, items in `-` lists are also considered incomplete todos and items in `+` lists complete ones
+// (Bear's own todo markup); otherwise only `[ ]` and `[x]` items are todos.
+func Parse(source []byte, bearSyntax bool) []*Item {
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+	items := make([]*Item, 0)
+	heading := ""
+
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch n := node.(type) {
+		case *ast.Heading:
+			heading = string(blockText(n, source))
+			return ast.WalkSkipChildren, nil
+		case *ast.ListItem:
+			if item := parseItem(n, source, bearSyntax); item != nil {
+				item.Heading = heading
+				item.Due = parseDue(item.Text)
+				items = append(items, item)
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return items
+}
+
+// Incomplete returns the items that haven't been checked off
+func Incomplete(items []*Item) []*Item {
+	open := make([]*Item, 0)
+
+	for _, item := range items {
+		if !item.Done {
+			open = append(open, item)
+		}
+	}
+
+	return open
+}
+
+// Overdue returns true if the item is incomplete and its due date is before the day of now
+func (i *Item) Overdue(now time.Time) bool {
+	if i.Done || i.Due.IsZero() {
+		return false
+	}
+
+	return i.Due.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+}
+
+// Markdown renders the items as a Markdown task list
+func Markdown(items []*Item) string {
+	b := strings.Builder{}
+
+	for _, item := range items {
+		if item.Done {
+			b.WriteString("- [x] ")
+		} else {
+			b.WriteString("- [ ] ")
+		}
+		b.WriteString(item.Text)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func parseItem(node *ast.ListItem, source []byte, bearSyntax bool) *Item {
+	block := node.FirstChild()
+	if block == nil {
+		return nil
+	}
+
+	line := blockText(block, source)
+
+	if bytes.HasPrefix(line, incompleteMarker) {
+		return &Item{Text: itemText(line), Done: false}
+	}
+
+	for _, marker := range completeMarkers {
+		if bytes.HasPrefix(line, marker) {
+			return &Item{Text: itemText(line), Done: true}
+		}
+	}
+
+	if list, ok := node.Parent().(*ast.List); ok && bearSyntax && len(line) > 0 {
+		switch list.Marker {
+		case '-':
+			return &Item{Text: string(line), Done: false}
+		case '+':
+			return &Item{Text: string(line), Done: true}
+		}
+	}
+
+	return nil
+}
+
+// blockText returns the raw source of a block's lines, joined with spaces
+func blockText(node ast.Node, source []byte) []byte {
+	lines := node.Lines()
+	parts := make([][]byte, 0, lines.Len())
+
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		parts = append(parts, bytes.TrimSpace(segment.Value(source)))
+	}
+
+	return bytes.Join(parts, []byte(" "))
+}
+
+// parseDue returns the first inline due date in the text, or the zero time if there isn't one
+func parseDue(text string) time.Time {
+	m := dueRegex.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}
+	}
+
+	due, err := time.ParseInLocation(dueFormat, m[1], time.Local)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return due
+}
+
+func itemText(line []byte) string {
+	return string(bytes.TrimSpace(line[len(incompleteMarker):]))
+}
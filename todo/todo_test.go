@@ -0,0 +1,91 @@
+package todo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	markdown := `# 2024-03-01
+- [ ] call **bob**
+- [x] done thing
+- not a task
+
+## Work
+* [ ] review [the PR](https://example.com)
+  - [X] nested and done
+  - [ ] nested
+    and wrapped
+
+1. [ ] numbered
+`
+
+	items := Parse([]byte(markdown), false)
+
+	assert.Equal(t, 6, len(items), items)
+
+	assert.Equal(t, &Item{Text: "call **bob**", Done: false, Heading: "2024-03-01"}, items[0])
+	assert.Equal(t, &Item{Text: "done thing", Done: true, Heading: "2024-03-01"}, items[1])
+	assert.Equal(t, &Item{Text: "review [the PR](https://example.com)", Done: false, Heading: "Work"}, items[2])
+	assert.Equal(t, &Item{Text: "nested and done", Done: true, Heading: "Work"}, items[3])
+	assert.Equal(t, &Item{Text: "nested and wrapped", Done: false, Heading: "Work"}, items[4])
+	assert.Equal(t, "numbered", items[5].Text)
+
+	open := Incomplete(items)
+	assert.Equal(t, 4, len(open))
+}
+
+func TestParseNoTasks(t *testing.T) {
+	assert.Empty(t, Parse([]byte("# Title\nJust text\n- a bullet\n"), false))
+	assert.Empty(t, Parse([]byte(""), false))
+}
+
+func TestParseBearSyntax(t *testing.T) {
+	markdown := `# Title
+- open
++ done
+* bullet
+- [x] checked
+
+1. numbered
+`
+
+	items := Parse([]byte(markdown), true)
+
+	assert.Equal(t, 3, len(items), items)
+	assert.Equal(t, &Item{Text: "open", Done: false, Heading: "Title"}, items[0])
+	assert.Equal(t, &Item{Text: "done", Done: true, Heading: "Title"}, items[1])
+	assert.Equal(t, &Item{Text: "checked", Done: true, Heading: "Title"}, items[2])
+}
+
+func TestParseDue(t *testing.T) {
+	markdown := `- [ ] file taxes @due(2024-04-15)
+- [ ] renew passport 📅 2024-05-01
+- [x] done @due(2024-01-01)
+- [ ] no date
+- [ ] bad date @due(2024-13-45)
+`
+
+	items := Parse([]byte(markdown), false)
+	now := time.Date(2024, 4, 20, 12, 0, 0, 0, time.Local)
+
+	assert.Equal(t, time.Date(2024, 4, 15, 0, 0, 0, 0, time.Local), items[0].Due)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local), items[1].Due)
+	assert.True(t, items[3].Due.IsZero())
+	assert.True(t, items[4].Due.IsZero())
+
+	assert.True(t, items[0].Overdue(now))
+	assert.False(t, items[1].Overdue(now))
+	assert.False(t, items[2].Overdue(now), "completed items aren't overdue")
+	assert.False(t, items[3].Overdue(now))
+	assert.False(t, items[0].Overdue(time.Date(2024, 4, 15, 23, 0, 0, 0, time.Local)), "due today isn't overdue")
+}
+
+func TestMarkdown(t *testing.T) {
+	items := []*Item{{Text: "a"}, {Text: "b", Done: true}}
+
+	assert.Equal(t, "- [ ] a\n- [x] b\n", Markdown(items))
+	assert.Equal(t, "", Markdown(nil))
+}
@@ -1,13 +1,19 @@
 package util
 
 import (
+	"fmt"
 	"log"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/pkg/errors"
 )
 
+var ageRegex = regexp.MustCompile(`^(\d+)([dw])$`)
+
 // RemoveIntermediatePrefixes removes the set of intermediate prefixes. If [a a/b c] is passed in,
 // then [a/b c] is returned; `a` was removed because it's an intermediate prefix of `a/b` (given a separator of /).
 func RemoveIntermediatePrefixes(strs []string, sep string) []string {
@@ -98,3 +104,57 @@ func MustString(s string, e error) string {
 
 	return s
 }
+
+// ParseAge parses an age in days or weeks, such as 30d or 2w, or a Go duration such as 72h
+func ParseAge(s string) (time.Duration, error) {
+	if m := ageRegex.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return 0, errors.WithStack(err)
+		}
+		if m[2] == "w" {
+			n *= 7
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age: %s", s)
+	}
+
+	return d, nil
+}
+
+// HumanSize formats a number of bytes, e.g. 1.5 MB
+func HumanSize(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// Pluralize formats a count of a noun, e.g. 1 note or 2 notes
+func Pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// Limit returns the first n items, or all of them if n isn't positive
+func Limit[T any](items []T, n int) []T {
+	if n > 0 && len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -54,6 +55,44 @@ func TestUniqueSet(t *testing.T) {
 	assert.Contains(t, set1, "b")
 }
 
+func TestParseAge(t *testing.T) {
+	age, err := ParseAge("30d")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, age)
+
+	age, err = ParseAge("2w")
+	assert.NoError(t, err)
+	assert.Equal(t, 14*24*time.Hour, age)
+
+	age, err = ParseAge("72h")
+	assert.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, age)
+
+	_, err = ParseAge("a month")
+	assert.Error(t, err)
+}
+
+func TestHumanSize(t *testing.T) {
+	assert.Equal(t, "512 B", HumanSize(512))
+	assert.Equal(t, "1.0 KB", HumanSize(1024))
+	assert.Equal(t, "1.5 MB", HumanSize(1024*1024*3/2))
+	assert.Equal(t, "2.0 GB", HumanSize(2*1024*1024*1024))
+}
+
+func TestPluralize(t *testing.T) {
+	assert.Equal(t, "0 notes", Pluralize(0, "note"))
+	assert.Equal(t, "1 note", Pluralize(1, "note"))
+	assert.Equal(t, "2 notes", Pluralize(2, "note"))
+}
+
+func TestLimit(t *testing.T) {
+	items := []int{1, 2, 3}
+
+	assert.Equal(t, []int{1, 2}, Limit(items, 2))
+	assert.Equal(t, items, Limit(items, 5))
+	assert.Equal(t, items, Limit(items, 0))
+}
+
 func BenchmarkRemoveIntermediatePrefixes(t *testing.B) {
 	tests := [][]string{
 		{"fred", "fred/bear", "readings", "work", "work/coffee", "work/coffee/africa"},